@@ -4,6 +4,39 @@ package config
 type Config struct {
 	// Sections is a list of section available.
 	Sections map[string]Section
+	// Scalars is a list of messages that are documented as basic types.
+	Scalars []Scalar
+	// ExamplesDir is the directory of example fixtures of endpoints, or an
+	// empty string if there is none. Each fixture is a file named
+	// "<method full name>.json", such as "shop.v1.Shop.GetItem.json",
+	// containing an object with the example "request" and "response".
+	ExamplesDir string
+	// BaseURL is the URL that HTTP routes are relative to in code samples,
+	// such as "https://api.example.com". Its host is also the server of gRPC
+	// code samples.
+	BaseURL string
+	// Version is the version of the API, such as "1.0.0", written in the
+	// OpenAPI document.
+	Version string
+}
+
+// Scalar maps a message to a basic type, such as google.type.Money which is
+// documented as a single value instead of a message. It is defined by a
+// section named "scalar:<id>" where the ID is arbitrary.
+type Scalar struct {
+	// Message is the fully qualified name of the message.
+	Message string
+	// DisplayName is the name of the basic type.
+	DisplayName string
+	// JSONType is the JSON type of the value, such as "string" or "object".
+	JSONType string
+	// Format is the format of the value as named in JSON Schema, such as
+	// "uuid".
+	Format string
+	// Description describes the JSON representation.
+	Description string
+	// Example is an example of the value in JSON.
+	Example string
 }
 
 // Section is a part of the documentation as defined. Each section will output
@@ -20,4 +53,14 @@ type Section struct {
 	// Weight is an arbitrary number defining the order of the sections. Lower
 	// numbers should be placed nearer to the front.
 	Weight int
+	// ExcludeGRPC excludes endpoints that are only available over gRPC from
+	// the section.
+	ExcludeGRPC bool
+	// FilterFieldBehavior hides OUTPUT_ONLY fields from endpoint requests and
+	// INPUT_ONLY fields from endpoint responses, including the fields of the
+	// messages they reference.
+	FilterFieldBehavior bool
+	// StripEnumPrefix displays enum values without the prefix shared by all
+	// values of the enum, such as "ORDER_STATUS_".
+	StripEnumPrefix bool
 }
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -32,9 +34,22 @@ func Load(folderPath string, r io.Reader) (*Config, error) {
 	}
 	cfg := &Config{
 		Sections: make(map[string]Section),
+		BaseURL:  DefaultBaseURL,
+		Version:  DefaultVersion,
 	}
 	for _, s := range f.AllSections() {
 		if s.Name() == "" {
+			if err := loadRoot(folderPath, s, cfg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(s.Name(), scalarPrefix) {
+			scalar, err := loadScalar(s)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Scalars = append(cfg.Scalars, scalar)
 			continue
 		}
 		sect, err := loadSection(folderPath, s)
@@ -46,6 +61,88 @@ func Load(folderPath string, r io.Reader) (*Config, error) {
 	return cfg, nil
 }
 
+// DefaultBaseURL is the base URL of code samples if it is not configured.
+const DefaultBaseURL = "https://api.example.com"
+
+// DefaultVersion is the version of the API if it is not configured.
+const DefaultVersion = "1.0.0"
+
+// loadRoot loads the keys that are not in any section.
+func loadRoot(folderPath string, s *parser.Section, cfg *Config) error {
+	for _, k := range s.Keys() {
+		v := s.Get(k)
+		switch k {
+		default:
+			return fmt.Errorf("unknown key %q outside of sections", k)
+		case "examples":
+			cfg.ExamplesDir = filepath.Join(folderPath, v)
+		case "base_url":
+			u, err := url.Parse(v)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("base_url %q is not an absolute URL", v)
+			}
+			cfg.BaseURL = v
+		case "version":
+			if v == "" {
+				return fmt.Errorf("version may not be empty")
+			}
+			cfg.Version = v
+		}
+	}
+	return nil
+}
+
+// scalarPrefix is the prefix of the name of sections defining a Scalar.
+const scalarPrefix = "scalar:"
+
+// jsonTypes are the JSON types that a scalar may be, as named in JSON Schema.
+var jsonTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"null":    true,
+}
+
+// loadScalar loads the scalar section.
+func loadScalar(s *parser.Section) (Scalar, error) {
+	scalar := Scalar{}
+	for _, k := range s.Keys() {
+		v := s.Get(k)
+		switch k {
+		default:
+			return Scalar{}, fmt.Errorf("unknown key %q in section %q", k, s.Name())
+		case "message":
+			scalar.Message = v
+		case "name":
+			scalar.DisplayName = v
+		case "json":
+			if !jsonTypes[v] {
+				return Scalar{}, fmt.Errorf("json %q not a JSON type in section %q", v, s.Name())
+			}
+			scalar.JSONType = v
+		case "format":
+			scalar.Format = v
+		case "description":
+			scalar.Description = v
+		case "example":
+			if !json.Valid([]byte(v)) {
+				return Scalar{}, fmt.Errorf("example not valid JSON in section %q", s.Name())
+			}
+			scalar.Example = v
+		}
+	}
+	if scalar.Message == "" {
+		return Scalar{}, fmt.Errorf("message not specified in section %q", s.Name())
+	}
+	if scalar.DisplayName == "" {
+		scalar.DisplayName = scalar.Message[strings.LastIndexByte(scalar.Message, '.')+1:]
+	}
+	return scalar, nil
+}
+
 // loadSection loads the configuration section.
 func loadSection(folderPath string, s *parser.Section) (Section, error) {
 	sect := Section{}
@@ -71,6 +168,24 @@ func loadSection(folderPath string, s *parser.Section) (Section, error) {
 				return Section{}, fmt.Errorf("cannot read preamble file: %w", err)
 			}
 			sect.PreambleContent = string(content)
+		case "grpc":
+			include, err := strconv.ParseBool(v)
+			if err != nil {
+				return Section{}, fmt.Errorf("grpc not a boolean in section %q", s.Name())
+			}
+			sect.ExcludeGRPC = !include
+		case "filter_field_behavior":
+			var err error
+			sect.FilterFieldBehavior, err = strconv.ParseBool(v)
+			if err != nil {
+				return Section{}, fmt.Errorf("filter_field_behavior not a boolean in section %q", s.Name())
+			}
+		case "strip_enum_prefix":
+			var err error
+			sect.StripEnumPrefix, err = strconv.ParseBool(v)
+			if err != nil {
+				return Section{}, fmt.Errorf("strip_enum_prefix not a boolean in section %q", s.Name())
+			}
 		case "weight":
 			var err error
 			sect.Weight, err = strconv.Atoi(v)
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadScalar(t *testing.T) {
+	cfg, err := Load("", strings.NewReader(`
+[scalar:money]
+message = google.type.Money
+json = string
+example = "12.50 USD"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Scalar{Message: "google.type.Money", DisplayName: "Money", JSONType: "string", Example: `"12.50 USD"`}
+	if len(cfg.Scalars) != 1 || cfg.Scalars[0] != want {
+		t.Errorf("scalars = %+v, want %+v", cfg.Scalars, want)
+	}
+}
+
+func TestLoadScalarErrors(t *testing.T) {
+	tests := []struct {
+		section string
+		err     string
+	}{
+		{"json = string", "message not specified"},
+		{"message = a.B\njson = text", `json "text" not a JSON type`},
+		{"message = a.B\nexample = 12.50 USD", "example not valid JSON"},
+		{"message = a.B\ntype = string", `unknown key "type"`},
+	}
+	for _, test := range tests {
+		_, err := Load("", strings.NewReader("[scalar:x]\n"+test.section))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Load(%q) error = %v, want %q", test.section, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package diag collects diagnostics reported while generating documentation so
+// that every problem can be reported in a single run.
+package diag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position in a .proto file.
+type Pos struct {
+	// File is the path of the file.
+	File string
+	// Line is the 1-based line number, or 0 if it is unknown.
+	Line int
+	// Column is the 1-based column number, or 0 if it is unknown.
+	Column int
+}
+
+// String returns the position in the form of "file:line:column", omitting
+// the parts that are unknown.
+func (p Pos) String() string {
+	switch {
+	case p.File == "":
+		return ""
+	case p.Line == 0:
+		return p.File
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// Diagnostic is a problem found during generation.
+type Diagnostic struct {
+	// Pos is the position that the problem is found at.
+	Pos Pos
+	// Message is the description of the problem.
+	Message string
+	// Warning is true if the problem does not stop generation.
+	Warning bool
+}
+
+// String returns the diagnostic prefixed by its position.
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Warning {
+		msg = "warning: " + msg
+	}
+	if pos := d.Pos.String(); pos != "" {
+		msg = pos + ": " + msg
+	}
+	return msg
+}
+
+// List is a list of diagnostics. The zero value is an empty list ready to use.
+type List struct {
+	errs     []Diagnostic
+	warnings []Diagnostic
+}
+
+// Errorf reports an error at the position.
+func (l *List) Errorf(pos Pos, format string, args ...interface{}) {
+	l.errs = append(l.errs, Diagnostic{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Warnf reports a warning at the position.
+func (l *List) Warnf(pos Pos, format string, args ...interface{}) {
+	l.warnings = append(l.warnings, Diagnostic{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+		Warning: true,
+	})
+}
+
+// Warnings returns the warnings reported so far.
+func (l *List) Warnings() []Diagnostic {
+	return l.warnings
+}
+
+// Err returns an error containing every error reported so far, or nil if no
+// error is reported.
+func (l *List) Err() error {
+	if len(l.errs) == 0 {
+		return nil
+	}
+	return Errors(l.errs)
+}
+
+// Errors is a list of errors reported during generation.
+type Errors []Diagnostic
+
+// Error returns every error, one per line.
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, d := range e {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
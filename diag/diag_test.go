@@ -0,0 +1,38 @@
+package diag
+
+import "testing"
+
+func TestPosString(t *testing.T) {
+	tests := []struct {
+		pos  Pos
+		want string
+	}{
+		{Pos{}, ""},
+		{Pos{File: "shop.proto"}, "shop.proto"},
+		{Pos{File: "shop.proto", Line: 12, Column: 3}, "shop.proto:12:3"},
+	}
+	for _, test := range tests {
+		if got := test.pos.String(); got != test.want {
+			t.Errorf("%#v.String() = %q, want %q", test.pos, got, test.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	var l List
+	if err := l.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+	l.Errorf(Pos{File: "a.proto", Line: 1, Column: 2}, "first %d", 1)
+	l.Warnf(Pos{File: "a.proto", Line: 3, Column: 4}, "careful")
+	l.Errorf(Pos{File: "b.proto"}, "second")
+	l.Errorf(Pos{}, "third")
+	want := "a.proto:1:2: first 1\nb.proto: second\nthird"
+	if err := l.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %q", err, want)
+	}
+	warnings := l.Warnings()
+	if len(warnings) != 1 || warnings[0].String() != "a.proto:3:4: warning: careful" {
+		t.Errorf("warnings = %v, want the warning at a.proto:3:4", warnings)
+	}
+}
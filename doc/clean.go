@@ -1,26 +1,35 @@
 package doc
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 )
 
 // PruneTypes prunes unused types from the package by walking all endpoints.
-// Types that are not used in requests or responses are removed.
-func PruneTypes(pkgs []*Package) {
-	usedTypes := make(map[string]bool)
+// Types that are not used in requests or responses are removed, except for
+// the messages that used types are nested in. References
+// to types outside of the packages are reported to d as warnings.
+func PruneTypes(pkgs []*Package, d *diag.List) {
+	p := &pruner{
+		pkgs:       pkgs,
+		diags:      d,
+		usedTypes:  make(map[string]bool),
+		visited:    make(map[string]bool),
+		unresolved: make(map[string]bool),
+	}
 	for _, pkg := range pkgs {
 		for _, srv := range pkg.Services {
 			for _, endpoint := range srv.Endpoints {
-				markUsedTypes(pkgs, usedTypes, endpoint.Request, true)
-				markUsedTypes(pkgs, usedTypes, endpoint.Response, true)
+				p.markUsedTypes(endpoint.Request)
+				p.markUsedTypes(endpoint.Response)
 			}
 		}
 	}
 	for _, pkg := range pkgs {
 		newTyp := make(map[string]Type)
 		for k, v := range pkg.Types {
-			if usedTypes[k] {
+			if p.usedTypes[pkg.ID+"."+k] {
 				newTyp[k] = v
 			}
 		}
@@ -28,35 +37,75 @@ func PruneTypes(pkgs []*Package) {
 	}
 }
 
-// markUsedTypes marks the specified types and all types it refers to as used in the usedTypes map.
-func markUsedTypes(pkgs []*Package, usedTypes map[string]bool, t Type, topLevel bool) {
-	if !topLevel {
-		if t, ok := t.(NamedType); ok {
-			usedTypes[t.TypeName()] = true
-		}
-	}
+// pruner is the state of PruneTypes.
+type pruner struct {
+	pkgs  []*Package
+	diags *diag.List
+	// usedTypes is the set of fully qualified names of types that are used.
+	usedTypes map[string]bool
+	// visited is the set of references that have been walked.
+	visited map[string]bool
+	// unresolved is the set of references that have been reported as
+	// unresolved.
+	unresolved map[string]bool
+}
+
+// markUsedTypes marks all types the specified type refers to as used.
+func (p *pruner) markUsedTypes(t Type) {
 	switch t := t.(type) {
 	default:
-		panic(fmt.Sprintf("unknown type: %T", t))
+		p.diags.Errorf(diag.Pos{}, "unknown type %T", t)
 	case *Enum, *Basic:
 	case *Array:
-		markUsedTypes(pkgs, usedTypes, t.Value, false)
+		p.markUsedTypes(t.Value)
 	case *Map:
-		markUsedTypes(pkgs, usedTypes, t.Key, false)
-		markUsedTypes(pkgs, usedTypes, t.Value, false)
+		p.markUsedTypes(t.Key)
+		p.markUsedTypes(t.Value)
 	case *Ref:
-		typ := resolveRef(pkgs, t)
-		markUsedTypes(pkgs, usedTypes, typ, false)
+		if p.visited[t.Name] {
+			return
+		}
+		p.visited[t.Name] = true
+		typ, ok := resolveRef(p.pkgs, t)
+		if !ok {
+			if !p.unresolved[t.Name] {
+				p.unresolved[t.Name] = true
+				p.diags.Warnf(t.Pos, "type %q is not documented", t.Name)
+			}
+			return
+		}
+		p.usedTypes[t.Name] = true
+		p.markUsedTypes(typ)
+		// Nested types link to the message they are nested in, which is
+		// kept even if it is only used through a variant.
+		if name, parent := scope(typ); parent != "" {
+			p.markUsedTypes(&Ref{
+				Name: strings.TrimSuffix(t.Name, name) + parent,
+				Pos:  t.Pos,
+			})
+		}
 	case *Message:
 		for _, f := range t.Fields {
-			markUsedTypes(pkgs, usedTypes, f.Type, false)
+			p.markUsedTypes(f.Type)
 		}
 	}
 }
 
-// resolveRef resolves the reference type given the packages. It panics if the
-// reference is not resolved.
-func resolveRef(pkgs []*Package, ref *Ref) Type {
+// scope returns the name of the named type and the name of the message it is
+// nested in, which is empty if it is not nested.
+func scope(t Type) (name, parent string) {
+	switch t := t.(type) {
+	case *Message:
+		return t.Name, t.Parent
+	case *Enum:
+		return t.Name, t.Parent
+	}
+	return "", ""
+}
+
+// resolveRef resolves the reference type given the packages. It returns false
+// if the reference is not found in any of the packages.
+func resolveRef(pkgs []*Package, ref *Ref) (Type, bool) {
 	for _, pkg := range pkgs {
 		prefix := pkg.ID + "."
 		if !strings.HasPrefix(ref.Name, prefix) {
@@ -65,18 +114,8 @@ func resolveRef(pkgs []*Package, ref *Ref) Type {
 		name := strings.TrimPrefix(ref.Name, prefix)
 		typ, ok := pkg.Types[name]
 		if ok {
-			return typ
-		}
-	}
-	for _, pkg := range pkgs {
-		prefix := pkg.ID + "."
-		if !strings.HasPrefix(ref.Name, prefix) {
-			continue
-		}
-		keys := make([]string, 0)
-		for k := range pkg.Types {
-			keys = append(keys, k)
+			return typ, true
 		}
 	}
-	panic("ref type not found: " + ref.Name)
+	return nil, false
 }
@@ -0,0 +1,77 @@
+package doc
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
+
+// endpointPackage returns a package with an endpoint using the request and
+// response.
+func endpointPackage(id string, types map[string]Type, req, resp Type) *Package {
+	return &Package{
+		ID:    id,
+		Types: types,
+		Services: []*Service{{
+			Name: "Service",
+			Endpoints: []*Endpoint{{
+				Name:     "Method",
+				Request:  req,
+				Response: resp,
+			}},
+		}},
+	}
+}
+
+// typeNames returns the sorted names of the types in the package.
+func typeNames(pkg *Package) string {
+	names := make([]string, 0, len(pkg.Types))
+	for name := range pkg.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestPruneTypes(t *testing.T) {
+	node := &Message{
+		Name: "Node",
+		Fields: []*Field{
+			{Name: "children", Type: &Array{Value: &Ref{Name: "a.Node"}}},
+		},
+	}
+	a := endpointPackage("a", map[string]Type{
+		"Book":   &Message{Name: "Book"},
+		"Node":   node,
+		"Unused": &Message{Name: "Unused"},
+	}, &Message{
+		Name: "Request",
+		Fields: []*Field{
+			{Name: "book", Type: &Ref{Name: "a.Book"}},
+			{Name: "node", Type: &Ref{Name: "a.Node"}},
+			{Name: "date", Type: &Ref{Name: "c.Date", Pos: diag.Pos{File: "a.proto", Line: 7, Column: 3}}},
+			{Name: "dates", Type: &Array{Value: &Ref{Name: "c.Date", Pos: diag.Pos{File: "a.proto", Line: 8, Column: 3}}}},
+		},
+	}, &Message{Name: "Response"})
+	// b has a type with the same name as a used type of a.
+	b := &Package{
+		ID: "b",
+		Types: map[string]Type{
+			"Book": &Message{Name: "Book"},
+		},
+	}
+	var d diag.List
+	PruneTypes([]*Package{a, b}, &d)
+	if got, want := typeNames(a), "Book,Node"; got != want {
+		t.Errorf("types of a = %q, want %q", got, want)
+	}
+	if got := typeNames(b); got != "" {
+		t.Errorf("types of b = %q, want none", got)
+	}
+	warnings := d.Warnings()
+	if len(warnings) != 1 || warnings[0].String() != `a.proto:7:3: warning: type "c.Date" is not documented` {
+		t.Errorf("warnings = %v, want c.Date reported once at its first use", warnings)
+	}
+}
@@ -0,0 +1,120 @@
+package doc
+
+// MarkRecursive marks every message in the packages that can contain itself
+// as recursive, along with the references that lead back into the same cycle.
+// It must be called before types are pruned or copied, since the requests and
+// responses of endpoints are only marked as the types of their packages.
+//
+// Cycles are found as the strongly connected components of the graph of
+// messages referencing each other using Tarjan's algorithm.
+func MarkRecursive(pkgs []*Package) {
+	c := &cycleFinder{
+		msgs:    make(map[string]*Message),
+		index:   make(map[string]int),
+		lowLink: make(map[string]int),
+		onStack: make(map[string]bool),
+		comp:    make(map[string]int),
+	}
+	for _, pkg := range pkgs {
+		for name, typ := range pkg.Types {
+			if msg, ok := typ.(*Message); ok {
+				c.msgs[pkg.ID+"."+name] = msg
+			}
+		}
+	}
+	for name := range c.msgs {
+		if _, ok := c.index[name]; !ok {
+			c.connect(name)
+		}
+	}
+	for name, msg := range c.msgs {
+		for _, ref := range fieldRefs(msg) {
+			target, ok := c.comp[ref.Name]
+			// Every reference within a component forms a cycle as only
+			// a message referencing itself can be alone in a cycle.
+			if !ok || target != c.comp[name] {
+				continue
+			}
+			ref.Recursive = true
+			msg.Recursive = true
+		}
+	}
+}
+
+// cycleFinder is the state of Tarjan's strongly connected components
+// algorithm.
+type cycleFinder struct {
+	msgs    map[string]*Message
+	next    int
+	index   map[string]int
+	lowLink map[string]int
+	stack   []string
+	onStack map[string]bool
+	// comp maps the name of each message to its component.
+	comp    map[string]int
+	nextCmp int
+}
+
+// connect visits the message with the name and everything reachable from it.
+func (c *cycleFinder) connect(name string) {
+	c.index[name] = c.next
+	c.lowLink[name] = c.next
+	c.next++
+	c.stack = append(c.stack, name)
+	c.onStack[name] = true
+	for _, ref := range fieldRefs(c.msgs[name]) {
+		if _, ok := c.msgs[ref.Name]; !ok {
+			continue
+		}
+		if _, ok := c.index[ref.Name]; !ok {
+			c.connect(ref.Name)
+			c.lowLink[name] = minInt(c.lowLink[name], c.lowLink[ref.Name])
+		} else if c.onStack[ref.Name] {
+			c.lowLink[name] = minInt(c.lowLink[name], c.index[ref.Name])
+		}
+	}
+	if c.lowLink[name] != c.index[name] {
+		return
+	}
+	comp := c.nextCmp
+	c.nextCmp++
+	for {
+		top := c.stack[len(c.stack)-1]
+		c.stack = c.stack[:len(c.stack)-1]
+		c.onStack[top] = false
+		c.comp[top] = comp
+		if top == name {
+			return
+		}
+	}
+}
+
+// fieldRefs returns every reference in the fields of the message, including
+// those used as array values or map values.
+func fieldRefs(msg *Message) []*Ref {
+	var refs []*Ref
+	var walk func(t Type)
+	walk = func(t Type) {
+		switch t := t.(type) {
+		case *Ref:
+			refs = append(refs, t)
+		case *Array:
+			walk(t.Value)
+		case *Map:
+			walk(t.Key)
+			walk(t.Value)
+		}
+	}
+	for _, f := range msg.Fields {
+		walk(f.Type)
+	}
+	return refs
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
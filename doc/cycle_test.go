@@ -0,0 +1,60 @@
+package doc
+
+import "testing"
+
+func TestMarkRecursive(t *testing.T) {
+	toExpr := &Ref{Name: "a.Expr"}
+	toArgs := &Ref{Name: "a.Args"}
+	toExprFromArgs := &Ref{Name: "a.Expr"}
+	toTree := &Ref{Name: "a.Tree"}
+	query := &Message{
+		Name:   "Query",
+		Fields: []*Field{{Name: "expr", Type: toExpr}},
+	}
+	expr := &Message{
+		Name:   "Expr",
+		Fields: []*Field{{Name: "args", Type: toArgs}},
+	}
+	args := &Message{
+		Name: "Args",
+		Fields: []*Field{
+			{Name: "values", Type: &Map{Key: &Basic{Name: "string"}, Value: toExprFromArgs}},
+		},
+	}
+	tree := &Message{
+		Name:   "Tree",
+		Fields: []*Field{{Name: "children", Type: &Array{Value: toTree}}},
+	}
+	pkg := endpointPackage("a", map[string]Type{
+		"Query": query,
+		"Expr":  expr,
+		"Args":  args,
+		"Tree":  tree,
+	}, tree, query)
+	MarkRecursive([]*Package{pkg})
+
+	e := pkg.Services[0].Endpoints[0]
+	if !e.Request.(*Message).Recursive {
+		t.Errorf("request Tree is not marked recursive")
+	}
+	if e.Response.(*Message).Recursive {
+		t.Errorf("response Query is marked recursive")
+	}
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"Expr", expr.Recursive, true},
+		{"Args", args.Recursive, true},
+		{"Query.expr", toExpr.Recursive, false},
+		{"Expr.args", toArgs.Recursive, true},
+		{"Args.values", toExprFromArgs.Recursive, true},
+		{"Tree.children", toTree.Recursive, true},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s recursive = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package doc
 
+import (
+	"encoding/json"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
+
 // Tag contains the packages for a specific tag, as well as the preamble
 // information.
 type Tag struct {
@@ -13,6 +19,17 @@ type Tag struct {
 	Packages []*Package `json:"packages"`
 }
 
+// Deprecation is the deprecation status of an element, either from the
+// deprecated option or from a "Deprecated: " paragraph in its comment.
+type Deprecation struct {
+	// Deprecated is true if the element is deprecated.
+	Deprecated bool `json:"deprecated"`
+	// DeprecationMessage is the explanation following "Deprecated: " in the
+	// comment, such as what to use instead. It may be empty even if the
+	// element is deprecated.
+	DeprecationMessage string `json:"deprecation_message"`
+}
+
 // Package is the documentation for a package.
 type Package struct {
 	// Name is the name of the package. It may not necessarily be unique.
@@ -22,6 +39,13 @@ type Package struct {
 	ID string `json:"id"`
 	// Description is the description of the comment.
 	Description string `json:"description"`
+	// RichDescription is the description parsed into blocks, or nil if
+	// there is no description.
+	RichDescription *RichText `json:"rich_description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// Files is the list of .proto files that declare the package.
+	Files []string `json:"files"`
 	// Services is a list of services in the package.
 	Services []*Service `json:"services"`
 	// Types is a list of data types in the package.
@@ -34,6 +58,11 @@ type Service struct {
 	Name string `json:"name"`
 	// Description is the description of the service.
 	Description string `json:"description"`
+	// RichDescription is the description parsed into blocks, or nil if
+	// there is no description.
+	RichDescription *RichText `json:"rich_description"`
+	// Deprecation is the deprecation status.
+	Deprecation
 	// Methods is a list of methods in the service.
 	Endpoints []*Endpoint `json:"endpoints"`
 }
@@ -44,18 +73,164 @@ type Endpoint struct {
 	Name string `json:"name"`
 	// Description is the description of the endpoint.
 	Description string `json:"description"`
-	// Method is the HTTP method to trigger the endpoint.
+	// RichDescription is the description parsed into blocks, or nil if
+	// there is no description.
+	RichDescription *RichText `json:"rich_description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// FullMethod is the full gRPC method name in the form of
+	// "/package.Service/Method".
+	FullMethod string `json:"full_method"`
+	// Method is the HTTP method of the primary route. It is empty if the
+	// endpoint is only available over gRPC.
 	Method string `json:"method"`
-	// Path is the HTTP path to trigger the endpoint.
+	// Path is the HTTP path of the primary route. It is empty if the
+	// endpoint is only available over gRPC.
 	Path string `json:"path"`
-	// BodyField is the name of the field that contains the request body.
+	// BodyField is the name of the field that contains the request body in
+	// the primary route.
 	BodyField string `json:"body_field"`
+	// Routes is the list of HTTP routes that trigger the endpoint, starting
+	// with the primary route followed by any additional bindings.
+	Routes []*Route `json:"routes"`
 	// Request is the data type of the request.
 	Request Type `json:"request"`
-	// Response is the data type of the response.
+	// RequestType is the fully qualified name of the request message.
+	RequestType string `json:"request_type"`
+	// Response is the data type of the response. If ResponseBodyField is
+	// set, it is the type of that field instead of the whole response
+	// message.
 	Response Type `json:"response"`
+	// ResponseType is the fully qualified name of the response message, even
+	// if only one of its fields is sent.
+	ResponseType string `json:"response_type"`
+	// ResponseBodyField is the name of the field of the response message that
+	// is sent as the response body, or empty if the whole message is sent.
+	ResponseBodyField string `json:"response_body_field"`
 	// StreamingRequest is true if the request is streamed.
 	StreamingRequest bool `json:"streaming_request"`
 	// StreamingResponse is true if the response is streamed.
 	StreamingResponse bool `json:"streaming_response"`
+	// Streaming is the streaming mode of the endpoint, one of the Streaming
+	// constants.
+	Streaming string `json:"streaming"`
+	// RequestExample is an example of the request message in JSON.
+	RequestExample json.RawMessage `json:"request_example"`
+	// ResponseExample is an example of the response in JSON.
+	ResponseExample json.RawMessage `json:"response_example"`
+	// Samples is a list of code samples calling the endpoint through its
+	// primary route, or through gRPC if it has no route.
+	Samples []*Sample `json:"samples"`
+	// Pos is the position of the method declaring the endpoint.
+	Pos diag.Pos `json:"-"`
+}
+
+// Sample is a code sample calling an endpoint.
+type Sample struct {
+	// Lang is the language of the code used for syntax highlighting, such as
+	// "shell" or "python".
+	Lang string `json:"lang"`
+	// Label is the name of the tool or language shown to readers, such as
+	// "curl" or "Python".
+	Label string `json:"label"`
+	// Code is the code of the sample.
+	Code string `json:"code"`
 }
+
+// Route is a HTTP route that triggers an endpoint.
+type Route struct {
+	// Method is the HTTP method to trigger the endpoint.
+	Method string `json:"method"`
+	// Path is the HTTP path to trigger the endpoint.
+	Path string `json:"path"`
+	// BodyField is the name of the field that contains the request body. It
+	// is "*" if the whole request message is the body.
+	BodyField string `json:"body_field"`
+	// Template is the parsed form of Path.
+	Template *PathTemplate `json:"template"`
+	// PathParams is the list of parameters bound by the variables in Path.
+	PathParams []*Param `json:"path_params"`
+	// QueryParams is the list of parameters passed in the query string.
+	QueryParams []*Param `json:"query_params"`
+	// BodyExample is an example of the request body in JSON, or nil if the
+	// route has no body.
+	BodyExample json.RawMessage `json:"body_example"`
+}
+
+// PathTemplate is a parsed HTTP path template.
+type PathTemplate struct {
+	// Segments is the list of segments separated by '/'. It is empty for
+	// the root path "/".
+	Segments []*PathSegment `json:"segments"`
+	// Verb is the custom verb following ':' at the end of the path, such as
+	// "publish", or empty if there is none.
+	Verb string `json:"verb"`
+}
+
+// PathSegment is a segment of a path template.
+type PathSegment struct {
+	// Kind is the kind of the segment, one of the Segment constants.
+	Kind string `json:"kind"`
+	// Value is the literal text of the segment, or "*" and "**" for
+	// wildcards. It is empty for variables.
+	Value string `json:"value"`
+	// Variable is the variable of the segment if Kind is SegmentVariable.
+	Variable *PathVariable `json:"variable"`
+}
+
+// Kinds of path segments.
+const (
+	SegmentLiteral      = "literal"
+	SegmentWildcard     = "wildcard"
+	SegmentDeepWildcard = "deep_wildcard"
+	SegmentVariable     = "variable"
+)
+
+// PathVariable is a variable in a path template that binds part of the path
+// to a field of the request.
+type PathVariable struct {
+	// FieldPath is the dot-separated path of the bound field using the proto
+	// field names.
+	FieldPath string `json:"field_path"`
+	// JSONPath is the dot-separated path of the bound field using the JSON
+	// field names, such as "book.displayName".
+	JSONPath string `json:"json_path"`
+	// Pattern is the pattern the variable matches, such as
+	// "projects/*/books/*". It is "*" if the variable has no pattern.
+	Pattern string `json:"pattern"`
+	// Segments is the parsed form of Pattern.
+	Segments []*PathSegment `json:"segments"`
+}
+
+// Param is a parameter of a route that is bound to a field of the request.
+type Param struct {
+	// Name is the name of the parameter. It is the field path using proto
+	// names for path parameters and using JSON names for query parameters.
+	Name string `json:"name"`
+	// JSONPath is the field path using JSON names, which are the names of
+	// the members of the request in JSON.
+	JSONPath string `json:"json_path"`
+	// Pattern is the pattern the parameter must match, or empty if there is
+	// no restriction.
+	Pattern string `json:"pattern"`
+	// Description is the description of the bound field.
+	Description string `json:"description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// Type is the type of the bound field. For repeated fields, it is the
+	// type of each value.
+	Type Type `json:"type"`
+	// Repeated is true if the parameter may be passed multiple times.
+	Repeated bool `json:"repeated"`
+	// Required is true if the bound field is required, either as a proto2
+	// required field or with the REQUIRED field behavior.
+	Required bool `json:"required"`
+}
+
+// Streaming modes of an endpoint.
+const (
+	StreamingUnary         = "unary"
+	StreamingClient        = "client"
+	StreamingServer        = "server"
+	StreamingBidirectional = "bidirectional"
+)
@@ -0,0 +1,333 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maxExampleDepth is the number of nested messages included in an example.
+// Messages nested deeper are shown as empty objects.
+const maxExampleDepth = 5
+
+// Fixture is a handwritten example of an endpoint.
+type Fixture struct {
+	// Request is the example request, or nil if it is generated.
+	Request json.RawMessage `json:"request"`
+	// Response is the example response, or nil if it is generated.
+	Response json.RawMessage `json:"response"`
+}
+
+// AddExamples attaches example JSON payloads to the endpoints of the packages
+// and to the bodies of their routes. References are resolved against the
+// packages. fixtures maps the full gRPC method name of endpoints to their
+// handwritten examples, which are used in place of generated ones.
+//
+// Generated examples contain the first field of each oneof and skip recursive
+// fields. OUTPUT_ONLY fields are left out of requests and INPUT_ONLY fields are
+// left out of responses.
+func AddExamples(pkgs []*Package, fixtures map[string]*Fixture) {
+	for _, pkg := range pkgs {
+		for _, srv := range pkg.Services {
+			for _, e := range srv.Endpoints {
+				addExamples(pkgs, e, fixtures[e.FullMethod])
+			}
+		}
+	}
+}
+
+// addExamples attaches the examples to the endpoint, preferring the ones of
+// the fixture if it is not nil. Examples of the fixture that are not valid
+// JSON are generated instead.
+func addExamples(pkgs []*Package, e *Endpoint, fixture *Fixture) {
+	var req, resp exampleValue
+	if fixture != nil && json.Valid(fixture.Request) {
+		req = parseExample(fixture.Request)
+	} else {
+		req = newExampleBuilder(pkgs, BehaviorOutputOnly).value(e.Request)
+	}
+	if fixture != nil && json.Valid(fixture.Response) {
+		// Fixtures contain the whole response message.
+		resp = parseExample(fixture.Response)
+		if obj, ok := resp.(exampleObject); ok && e.ResponseBodyField != "" {
+			resp = obj.get(e.ResponseBodyField)
+		}
+	} else {
+		resp = newExampleBuilder(pkgs, BehaviorInputOnly).value(e.Response)
+	}
+	e.RequestExample = req.json()
+	e.ResponseExample = resp.json()
+	for _, r := range e.Routes {
+		switch r.BodyField {
+		case "":
+		case "*":
+			body := req
+			for _, p := range r.PathParams {
+				body = body.without(strings.Split(p.JSONPath, "."))
+			}
+			r.BodyExample = body.json()
+		default:
+			if obj, ok := req.(exampleObject); ok {
+				r.BodyExample = obj.get(r.BodyField).json()
+			}
+		}
+	}
+}
+
+// exampleBuilder is the state of building an example.
+type exampleBuilder struct {
+	pkgs []*Package
+	// skip is the behavior of fields that are left out.
+	skip string
+	// visiting is the set of messages being built, used to skip recursive
+	// fields.
+	visiting map[string]bool
+	depth    int
+}
+
+func newExampleBuilder(pkgs []*Package, skip string) *exampleBuilder {
+	return &exampleBuilder{
+		pkgs:     pkgs,
+		skip:     skip,
+		visiting: make(map[string]bool),
+	}
+}
+
+// value returns the example of the type.
+func (b *exampleBuilder) value(t Type) exampleValue {
+	switch t := t.(type) {
+	case *Basic:
+		if !json.Valid([]byte(t.Example)) {
+			return exampleRaw("null")
+		}
+		return exampleRaw(t.Example)
+	case *Array:
+		return exampleArray{b.value(t.Value)}
+	case *Map:
+		return exampleObject{{key: mapKey(t.Key), value: b.value(t.Value)}}
+	case *Enum:
+		return exampleEnum(t)
+	case *Message:
+		if json.Valid(t.Example) {
+			return parseExample(t.Example)
+		}
+		return b.message(t)
+	case *Ref:
+		typ, ok := resolveRef(b.pkgs, t)
+		if !ok {
+			return exampleObject{}
+		}
+		if _, ok := typ.(*Message); ok {
+			if b.visiting[t.Name] || b.depth >= maxExampleDepth {
+				return exampleObject{}
+			}
+			b.visiting[t.Name] = true
+			defer delete(b.visiting, t.Name)
+		}
+		return b.value(typ)
+	}
+	return exampleRaw("null")
+}
+
+// message returns the example of the message.
+func (b *exampleBuilder) message(m *Message) exampleValue {
+	b.depth++
+	defer func() { b.depth-- }()
+	obj := exampleObject{}
+	oneofs := make(map[string]bool)
+	for _, f := range m.Fields {
+		if f.HasBehavior(b.skip) || b.recursive(f.Type) {
+			continue
+		}
+		if f.Oneof != "" {
+			if oneofs[f.Oneof] {
+				continue
+			}
+			oneofs[f.Oneof] = true
+		}
+		value := b.value(f.Type)
+		if json.Valid(f.Example) {
+			value = parseExample(f.Example)
+		}
+		obj = append(obj, exampleMember{key: f.Name, value: value})
+	}
+	return obj
+}
+
+// parseExample parses the JSON example, keeping the order of the members of
+// objects. Invalid JSON is parsed as null so that examples are always valid
+// JSON, which is why examples that may be invalid are validated beforehand to
+// fall back to a generated one.
+func parseExample(data json.RawMessage) exampleValue {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeExample(dec)
+	if err != nil {
+		return exampleRaw("null")
+	}
+	return v
+}
+
+// decodeExample decodes the next JSON value from the decoder.
+func decodeExample(dec *json.Decoder) (exampleValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		arr := exampleArray{}
+		for dec.More() {
+			v, err := decodeExample(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := dec.Token()
+		return arr, err
+	case json.Delim('{'):
+		obj := exampleObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeExample(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, exampleMember{key: key.(string), value: v})
+		}
+		_, err := dec.Token()
+		return obj, err
+	}
+	b, err := json.Marshal(tok)
+	return exampleRaw(b), err
+}
+
+// recursive returns true if the type refers to a message that is being built.
+func (b *exampleBuilder) recursive(t Type) bool {
+	switch t := t.(type) {
+	case *Array:
+		return b.recursive(t.Value)
+	case *Map:
+		return b.recursive(t.Value)
+	case *Ref:
+		return b.visiting[t.Name]
+	}
+	return false
+}
+
+// exampleEnum returns the first value of the enum that is neither deprecated
+// nor the default, which is usually UNSPECIFIED.
+func exampleEnum(e *Enum) exampleValue {
+	if len(e.Values) == 0 {
+		return exampleRaw("null")
+	}
+	v := e.Values[0]
+	for _, val := range e.Values {
+		if !val.Default && !val.Deprecated {
+			v = val
+			break
+		}
+	}
+	b, _ := json.Marshal(v.Value)
+	return exampleRaw(b)
+}
+
+// mapKey returns the example key of a map, which is always a string in JSON.
+func mapKey(t Type) string {
+	basic, ok := t.(*Basic)
+	if !ok || basic.Example == "" {
+		return "key"
+	}
+	var s string
+	if err := json.Unmarshal([]byte(basic.Example), &s); err == nil {
+		return s
+	}
+	return basic.Example
+}
+
+// exampleValue is a JSON value of an example that keeps the order of the
+// fields of messages.
+type exampleValue interface {
+	json() json.RawMessage
+	// without returns a copy of the example without the member at the path
+	// of keys. Values that are not objects are returned as is.
+	without(path []string) exampleValue
+}
+
+// exampleRaw is a JSON value written as is.
+type exampleRaw string
+
+// exampleArray is a JSON array.
+type exampleArray []exampleValue
+
+// exampleObject is a JSON object.
+type exampleObject []exampleMember
+
+// exampleMember is a member of a JSON object.
+type exampleMember struct {
+	key   string
+	value exampleValue
+}
+
+func (r exampleRaw) json() json.RawMessage {
+	return json.RawMessage(r)
+}
+
+func (a exampleArray) json() json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, v := range a {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(v.json())
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+func (o exampleObject) json() json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(m.value.json())
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// get returns the value of the member with the key, or null if there is none.
+func (o exampleObject) get(key string) exampleValue {
+	for _, m := range o {
+		if m.key == key {
+			return m.value
+		}
+	}
+	return exampleRaw("null")
+}
+
+func (r exampleRaw) without(path []string) exampleValue   { return r }
+func (a exampleArray) without(path []string) exampleValue { return a }
+
+func (o exampleObject) without(path []string) exampleValue {
+	newObj := make(exampleObject, 0, len(o))
+	for _, m := range o {
+		switch {
+		case m.key != path[0]:
+			newObj = append(newObj, m)
+		case len(path) > 1:
+			newObj = append(newObj, exampleMember{key: m.key, value: m.value.without(path[1:])})
+		}
+	}
+	return newObj
+}
@@ -0,0 +1,88 @@
+package doc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// bookEndpoint returns an endpoint updating a book whose ID is bound by the
+// path, with fields whose JSON names differ from their proto names.
+func bookEndpoint() *Endpoint {
+	book := &Message{
+		Name: "Book",
+		Fields: []*Field{
+			{Name: "id", Type: &Basic{Name: "String", Example: `"b1"`}},
+			{Name: "title", Type: &Basic{Name: "String", Example: `"Dune"`}},
+		},
+	}
+	return &Endpoint{
+		FullMethod: "/shop.Shop/UpdateBook",
+		Request: &Message{
+			Name:   "UpdateBookRequest",
+			Fields: []*Field{{Name: "theBook", Type: book}},
+		},
+		// The response is the type of the response body field while
+		// fixtures contain the whole response message.
+		Response:          book,
+		ResponseBodyField: "theBook",
+		Routes: []*Route{
+			{BodyField: "theBook"},
+			{BodyField: "*", PathParams: []*Param{{Name: "the_book.book_id", JSONPath: "theBook.id"}}},
+		},
+	}
+}
+
+func TestAddExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  *Fixture
+		request  string
+		response string
+		body     string
+		star     string
+	}{
+		{
+			name:     "generated",
+			request:  `{"theBook":{"id":"b1","title":"Dune"}}`,
+			response: `{"id":"b1","title":"Dune"}`,
+			body:     `{"id":"b1","title":"Dune"}`,
+			star:     `{"theBook":{"title":"Dune"}}`,
+		},
+		{
+			name: "fixture",
+			fixture: &Fixture{
+				Request:  json.RawMessage(`{"theBook": {"title": "Emma", "id": "b2"}}`),
+				Response: json.RawMessage(`{"theBook": {"id": "b2"}}`),
+			},
+			request:  `{"theBook":{"title":"Emma","id":"b2"}}`,
+			response: `{"id":"b2"}`,
+			body:     `{"title":"Emma","id":"b2"}`,
+			star:     `{"theBook":{"title":"Emma"}}`,
+		},
+		{
+			name: "invalid fixture",
+			fixture: &Fixture{
+				Request:  json.RawMessage(`{"theBook": `),
+				Response: json.RawMessage(`not json`),
+			},
+			request:  `{"theBook":{"id":"b1","title":"Dune"}}`,
+			response: `{"id":"b1","title":"Dune"}`,
+			body:     `{"id":"b1","title":"Dune"}`,
+			star:     `{"theBook":{"title":"Dune"}}`,
+		},
+	}
+	for _, test := range tests {
+		e := bookEndpoint()
+		addExamples(nil, e, test.fixture)
+		got := []string{
+			string(e.RequestExample), string(e.ResponseExample),
+			string(e.Routes[0].BodyExample), string(e.Routes[1].BodyExample),
+		}
+		want := []string{test.request, test.response, test.body, test.star}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: example %d = %s, want %s", test.name, i, got[i], want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package doc
+
+import "github.com/chanbakjsd/protoc-gen-doc/diag"
+
+// MergePackages merges packages with the same ID, such as those converted from
+// different files of the same proto package, into a single package. The order
+// of the first occurrence of each package is preserved. The merged package is
+// named after the first package with a name and packages with a different name
+// are reported to d as warnings.
+func MergePackages(pkgs []*Package, d *diag.List) []*Package {
+	merged := make([]*Package, 0, len(pkgs))
+	byID := make(map[string]*Package, len(pkgs))
+	// namedBy maps the ID of each package to the file that names it.
+	namedBy := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		target, ok := byID[pkg.ID]
+		if !ok {
+			target = pkg
+			byID[pkg.ID] = pkg
+			merged = append(merged, pkg)
+		} else {
+			target.merge(pkg)
+		}
+		switch file, named := namedBy[pkg.ID]; {
+		case pkg.Name == "":
+		case !named:
+			namedBy[pkg.ID] = pkg.Files[0]
+		case pkg.Name != target.Name:
+			d.Warnf(
+				diag.Pos{File: pkg.Files[0]}, "package %q is named %q instead of %q as in %s",
+				pkg.ID, pkg.Name, target.Name, file,
+			)
+		}
+	}
+	return merged
+}
+
+// merge merges the other package into the package.
+func (p *Package) merge(other *Package) {
+	if p.Name == "" {
+		p.Name = other.Name
+	}
+	switch {
+	case other.Description == "" || other.Description == p.Description:
+	case p.Description == "":
+		p.Description = other.Description
+	default:
+		p.Description += "\n\n" + other.Description
+	}
+	switch {
+	case other.RichDescription == nil:
+	case p.RichDescription == nil:
+		p.RichDescription = other.RichDescription
+	case p.RichDescription.Markdown != other.RichDescription.Markdown:
+		p.RichDescription = p.RichDescription.join(other.RichDescription)
+	}
+	// The package is only deprecated if all of its files are.
+	p.Deprecated = p.Deprecated && other.Deprecated
+	if p.DeprecationMessage == "" {
+		p.DeprecationMessage = other.DeprecationMessage
+	}
+	p.Files = append(p.Files, other.Files...)
+	p.Services = append(p.Services, other.Services...)
+	if p.Types == nil {
+		p.Types = make(map[string]Type, len(other.Types))
+	}
+	for k, v := range other.Types {
+		p.Types[k] = v
+	}
+}
@@ -0,0 +1,39 @@
+package doc
+
+import (
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
+
+func TestMergePackages(t *testing.T) {
+	pkgs := []*Package{
+		{ID: "shop", Files: []string{"shop/unnamed.proto"}},
+		{Name: "shoppb", ID: "shop", Files: []string{"shop/a.proto"}, Description: "Shop.", Services: []*Service{{Name: "A"}}},
+		{Name: "other", ID: "other", Files: []string{"other.proto"}},
+		{Name: "shoppb", ID: "shop", Files: []string{"shop/b.proto"}, Description: "Shop.", Services: []*Service{{Name: "B"}}},
+		{Name: "shop", ID: "shop", Files: []string{"shop/c.proto"}, Description: "More."},
+	}
+	var d diag.List
+	merged := MergePackages(pkgs, &d)
+	if len(merged) != 2 || merged[0].ID != "shop" || merged[1].ID != "other" {
+		t.Fatalf("merged packages = %v, want shop and other", merged)
+	}
+	shop := merged[0]
+	if shop.Name != "shoppb" {
+		t.Errorf("name = %q, want the name of the first named package", shop.Name)
+	}
+	if shop.Description != "Shop.\n\nMore." {
+		t.Errorf("description = %q, want distinct descriptions joined", shop.Description)
+	}
+	if len(shop.Files) != 4 || len(shop.Services) != 2 {
+		t.Errorf("files = %v and %d services, want all of them", shop.Files, len(shop.Services))
+	}
+	warnings := d.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %v, want the name of c.proto reported", warnings)
+	}
+	if want := `shop/c.proto: warning: package "shop" is named "shop" instead of "shoppb" as in shop/a.proto`; warnings[0].String() != want {
+		t.Errorf("warning = %q, want %q", warnings[0], want)
+	}
+}
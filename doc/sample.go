@@ -0,0 +1,388 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// sampleSegment is the value of wildcards in sample paths that are not bound
+// to an example value.
+const sampleSegment = "abc123"
+
+// AddSamples attaches code samples to the endpoints of the packages. HTTP
+// endpoints are called through their primary route in curl, HTTPie,
+// JavaScript, Python and Go while gRPC-only endpoints are called with
+// grpcurl. Samples are built from the examples of the endpoints, so
+// AddExamples must be called first. baseURL is the URL that routes are
+// relative to, such as "https://api.example.com".
+func AddSamples(pkgs []*Package, baseURL string) {
+	for _, pkg := range pkgs {
+		for _, srv := range pkg.Services {
+			for _, e := range srv.Endpoints {
+				e.Samples = endpointSamples(e, baseURL)
+			}
+		}
+	}
+}
+
+// endpointSamples returns the code samples of the endpoint.
+func endpointSamples(e *Endpoint, baseURL string) []*Sample {
+	var req exampleValue = exampleObject{}
+	if e.RequestExample != nil {
+		req = parseExample(e.RequestExample)
+	}
+	if len(e.Routes) == 0 {
+		return []*Sample{grpcurlSample(e, baseURL, req)}
+	}
+	r := e.Routes[0]
+	u := strings.TrimSuffix(baseURL, "/") + samplePath(r, req) + sampleQuery(r, req)
+	return []*Sample{
+		curlSample(r.Method, u, r.BodyExample),
+		httpieSample(r.Method, u, r.BodyExample),
+		fetchSample(r.Method, u, r.BodyExample),
+		pythonSample(r.Method, u, r.BodyExample),
+		goSample(r.Method, u, r.BodyExample),
+	}
+}
+
+// samplePath returns the path of the route with its variables replaced by the
+// values in the example request.
+func samplePath(r *Route, req exampleValue) string {
+	if r.Template == nil {
+		return r.Path
+	}
+	var b strings.Builder
+	for _, seg := range r.Template.Segments {
+		b.WriteByte('/')
+		switch seg.Kind {
+		case SegmentLiteral:
+			b.WriteString(seg.Value)
+		case SegmentVariable:
+			b.WriteString(sampleVariable(seg.Variable, req))
+		default:
+			b.WriteString(sampleSegment)
+		}
+	}
+	if len(r.Template.Segments) == 0 {
+		b.WriteByte('/')
+	}
+	if r.Template.Verb != "" {
+		b.WriteString(":" + r.Template.Verb)
+	}
+	return b.String()
+}
+
+// sampleVariable returns the value of the path variable. The example value of
+// the bound field is used if it matches the pattern of the variable.
+func sampleVariable(v *PathVariable, req exampleValue) string {
+	value, ok := exampleText(lookupExample(req, strings.Split(v.JSONPath, ".")))
+	if v.Pattern == "*" {
+		if !ok || value == "" {
+			value = sampleSegment
+		}
+		return url.PathEscape(value)
+	}
+	parts := strings.Split(value, "/")
+	if !ok || !matchSegments(v.Segments, parts) {
+		parts = parts[:0]
+		for _, seg := range v.Segments {
+			if seg.Kind == SegmentLiteral {
+				parts = append(parts, seg.Value)
+			} else {
+				parts = append(parts, sampleSegment)
+			}
+		}
+	}
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// matchSegments returns true if the parts of a path match the segments of a
+// pattern.
+func matchSegments(segs []*PathSegment, parts []string) bool {
+	if len(segs) == 0 {
+		return len(parts) == 0
+	}
+	if segs[0].Kind == SegmentDeepWildcard {
+		for n := 1; n <= len(parts); n++ {
+			if matchSegments(segs[1:], parts[n:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return false
+	}
+	if segs[0].Kind == SegmentLiteral && segs[0].Value != parts[0] {
+		return false
+	}
+	return matchSegments(segs[1:], parts[1:])
+}
+
+// sampleQuery returns the query string of the route with the query parameters
+// that are set in the example request, or an empty string if there is none.
+func sampleQuery(r *Route, req exampleValue) string {
+	q := make(url.Values)
+	for _, p := range r.QueryParams {
+		v := lookupExample(req, strings.Split(p.Name, "."))
+		values, ok := v.(exampleArray)
+		if !ok {
+			values = exampleArray{v}
+		}
+		for _, v := range values {
+			if text, ok := exampleText(v); ok {
+				q.Add(p.Name, text)
+			}
+		}
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// lookupExample returns the member of the example at the path of keys, or nil
+// if there is none.
+func lookupExample(v exampleValue, path []string) exampleValue {
+	for _, key := range path {
+		obj, ok := v.(exampleObject)
+		if !ok {
+			return nil
+		}
+		v = nil
+		for _, m := range obj {
+			if m.key == key {
+				v = m.value
+			}
+		}
+	}
+	return v
+}
+
+// exampleText returns the text of a string, number or boolean example as
+// written in URLs.
+func exampleText(v exampleValue) (string, bool) {
+	raw, ok := v.(exampleRaw)
+	if !ok || raw == "null" || raw == "" {
+		return "", false
+	}
+	if raw[0] != '"' {
+		return string(raw), true
+	}
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// indentJSON returns the JSON indented by two spaces, with every line after
+// the first prefixed by prefix.
+func indentJSON(data json.RawMessage, prefix string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, prefix, "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// shellQuote quotes the string for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// curlSample returns the sample calling the route with curl.
+func curlSample(method, u string, body json.RawMessage) *Sample {
+	lines := []string{"curl " + shellQuote(u)}
+	if method != "GET" || body != nil {
+		lines[0] = "curl -X " + method + " " + shellQuote(u)
+	}
+	if body != nil {
+		lines = append(lines,
+			"-H 'Content-Type: application/json'",
+			"-d "+shellQuote(indentJSON(body, "")),
+		)
+	}
+	return &Sample{
+		Lang:  "shell",
+		Label: "curl",
+		Code:  strings.Join(lines, " \\\n  "),
+	}
+}
+
+// httpieSample returns the sample calling the route with HTTPie, which sends
+// the standard input as a JSON body.
+func httpieSample(method, u string, body json.RawMessage) *Sample {
+	code := "http " + method + " " + shellQuote(u)
+	if body != nil {
+		code = "echo " + shellQuote(indentJSON(body, "")) + " \\\n  | " + code
+	}
+	return &Sample{
+		Lang:  "shell",
+		Label: "HTTPie",
+		Code:  code,
+	}
+}
+
+// fetchSample returns the sample calling the route with fetch in JavaScript.
+func fetchSample(method, u string, body json.RawMessage) *Sample {
+	var b strings.Builder
+	if method == "GET" && body == nil {
+		b.WriteString("const response = await fetch(" + jsonString(u) + ");\n")
+	} else {
+		b.WriteString("const response = await fetch(" + jsonString(u) + ", {\n")
+		b.WriteString("  method: " + jsonString(method) + ",\n")
+		if body != nil {
+			b.WriteString("  headers: {\n")
+			b.WriteString("    \"Content-Type\": \"application/json\",\n")
+			b.WriteString("  },\n")
+			b.WriteString("  body: JSON.stringify(" + indentJSON(body, "  ") + "),\n")
+		}
+		b.WriteString("});\n")
+	}
+	b.WriteString("const data = await response.json();")
+	return &Sample{
+		Lang:  "javascript",
+		Label: "JavaScript",
+		Code:  b.String(),
+	}
+}
+
+// pythonSample returns the sample calling the route with requests in Python.
+func pythonSample(method, u string, body json.RawMessage) *Sample {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	switch method {
+	case "GET", "PUT", "POST", "DELETE", "PATCH":
+		b.WriteString("response = requests." + strings.ToLower(method) + "(\n")
+	default:
+		b.WriteString("response = requests.request(\n")
+		b.WriteString("    " + jsonString(method) + ",\n")
+	}
+	b.WriteString("    " + jsonString(u) + ",\n")
+	if body != nil {
+		b.WriteString("    json=" + pythonLiteral(parseExample(body), "    ") + ",\n")
+	}
+	b.WriteString(")\n")
+	b.WriteString("print(response.json())")
+	return &Sample{
+		Lang:  "python",
+		Label: "Python",
+		Code:  b.String(),
+	}
+}
+
+// pythonLiteral returns the example as a Python literal, with every line after
+// the first prefixed by prefix.
+func pythonLiteral(v exampleValue, prefix string) string {
+	switch v := v.(type) {
+	case exampleObject:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, m := range v {
+			b.WriteString(prefix + "    " + jsonString(m.key) + ": " + pythonLiteral(m.value, prefix+"    ") + ",\n")
+		}
+		b.WriteString(prefix + "}")
+		return b.String()
+	case exampleArray:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, elem := range v {
+			b.WriteString(prefix + "    " + pythonLiteral(elem, prefix+"    ") + ",\n")
+		}
+		b.WriteString(prefix + "]")
+		return b.String()
+	case exampleRaw:
+		switch v {
+		case "true":
+			return "True"
+		case "false":
+			return "False"
+		case "null":
+			return "None"
+		}
+		return string(v)
+	}
+	return "None"
+}
+
+// goSample returns the sample calling the route with net/http in Go.
+func goSample(method, u string, body json.RawMessage) *Sample {
+	var b strings.Builder
+	reqBody := "nil"
+	if body != nil {
+		text := indentJSON(body, "")
+		if strings.Contains(text, "`") {
+			text = strconv.Quote(string(body))
+		} else {
+			text = "`" + text + "`"
+		}
+		b.WriteString("body := strings.NewReader(" + text + ")\n")
+		reqBody = "body"
+	}
+	b.WriteString("req, err := http.NewRequest(" + strconv.Quote(method) + ", " + strconv.Quote(u) + ", " + reqBody + ")\n")
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n")
+	if body != nil {
+		b.WriteString("req.Header.Set(\"Content-Type\", \"application/json\")\n")
+	}
+	b.WriteString("resp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n")
+	b.WriteString("defer resp.Body.Close()")
+	return &Sample{
+		Lang:  "go",
+		Label: "Go",
+		Code:  b.String(),
+	}
+}
+
+// grpcurlSample returns the sample calling the endpoint with grpcurl. The
+// server is assumed to listen on the host of baseURL, using TLS unless the
+// scheme is "http".
+func grpcurlSample(e *Endpoint, baseURL string, req exampleValue) *Sample {
+	host := baseURL
+	plaintext := false
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+		plaintext = u.Scheme == "http"
+		if u.Port() == "" && plaintext {
+			host += ":80"
+		} else if u.Port() == "" {
+			host += ":443"
+		}
+	}
+	lines := []string{"grpcurl"}
+	if plaintext {
+		lines[0] += " -plaintext"
+	}
+	lines = append(lines,
+		"-d "+shellQuote(indentJSON(req.json(), "")),
+		host+" "+strings.TrimPrefix(e.FullMethod, "/"),
+	)
+	return &Sample{
+		Lang:  "shell",
+		Label: "grpcurl",
+		Code:  strings.Join(lines, " \\\n  "),
+	}
+}
+
+// jsonString returns the string as a JSON string, which is also a valid
+// string literal in JavaScript and Python.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
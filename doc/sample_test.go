@@ -0,0 +1,237 @@
+package doc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", `''`},
+		{"https://api.example.com/v1/books?a=1&b=2", `'https://api.example.com/v1/books?a=1&b=2'`},
+		{`{"title": "Dune"}`, `'{"title": "Dune"}'`},
+		{`{"title": "Ender's Game"}`, `'{"title": "Ender'\''s Game"}'`},
+		{"''", `''\'''\'''`},
+		{"$HOME `id` \\n", "'$HOME `id` \\n'"},
+	}
+	for _, test := range tests {
+		if got := shellQuote(test.s); got != test.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestHTTPSamples(t *testing.T) {
+	const u = "https://api.example.com/v1/books"
+	tests := []struct {
+		name   string
+		sample func(method, u string, body json.RawMessage) *Sample
+		method string
+		body   string
+		want   string
+	}{
+		{"curl GET", curlSample, "GET", "", `curl 'https://api.example.com/v1/books'`},
+		{"curl DELETE", curlSample, "DELETE", "", `curl -X DELETE 'https://api.example.com/v1/books'`},
+		{
+			"curl quote", curlSample, "POST", `{"title":"Ender's Game"}`,
+			"curl -X POST 'https://api.example.com/v1/books' \\\n" +
+				"  -H 'Content-Type: application/json' \\\n" +
+				"  -d '{\n  \"title\": \"Ender'\\''s Game\"\n}'",
+		},
+		{"HTTPie GET", httpieSample, "GET", "", `http GET 'https://api.example.com/v1/books'`},
+		{
+			"HTTPie quote", httpieSample, "POST", `{"title":"Ender's Game"}`,
+			"echo '{\n  \"title\": \"Ender'\\''s Game\"\n}' \\\n" +
+				"  | http POST 'https://api.example.com/v1/books'",
+		},
+		{
+			"fetch GET", fetchSample, "GET", "",
+			"const response = await fetch(\"https://api.example.com/v1/books\");\n" +
+				"const data = await response.json();",
+		},
+		{
+			"fetch body", fetchSample, "POST", `{"title":"</script>"}`,
+			"const response = await fetch(\"https://api.example.com/v1/books\", {\n" +
+				"  method: \"POST\",\n" +
+				"  headers: {\n" +
+				"    \"Content-Type\": \"application/json\",\n" +
+				"  },\n" +
+				"  body: JSON.stringify({\n" +
+				"    \"title\": \"</script>\"\n" +
+				"  }),\n" +
+				"});\n" +
+				"const data = await response.json();",
+		},
+		{
+			"Python GET", pythonSample, "GET", "",
+			"import requests\n\n" +
+				"response = requests.get(\n" +
+				"    \"https://api.example.com/v1/books\",\n" +
+				")\n" +
+				"print(response.json())",
+		},
+		{
+			"Python custom method", pythonSample, "SEARCH", `{"done":true,"next":null}`,
+			"import requests\n\n" +
+				"response = requests.request(\n" +
+				"    \"SEARCH\",\n" +
+				"    \"https://api.example.com/v1/books\",\n" +
+				"    json={\n" +
+				"        \"done\": True,\n" +
+				"        \"next\": None,\n" +
+				"    },\n" +
+				")\n" +
+				"print(response.json())",
+		},
+		{
+			"Go GET", goSample, "GET", "",
+			"req, err := http.NewRequest(\"GET\", \"https://api.example.com/v1/books\", nil)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"resp, err := http.DefaultClient.Do(req)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"defer resp.Body.Close()",
+		},
+		{
+			"Go body", goSample, "POST", `{"title":"Dune"}`,
+			"body := strings.NewReader(`{\n  \"title\": \"Dune\"\n}`)\n" +
+				"req, err := http.NewRequest(\"POST\", \"https://api.example.com/v1/books\", body)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"req.Header.Set(\"Content-Type\", \"application/json\")\n" +
+				"resp, err := http.DefaultClient.Do(req)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"defer resp.Body.Close()",
+		},
+		{
+			// Raw strings cannot contain backticks.
+			"Go backtick", goSample, "POST", "{\"title\":\"`Dune`\"}",
+			"body := strings.NewReader(\"{\\\"title\\\":\\\"`Dune`\\\"}\")\n" +
+				"req, err := http.NewRequest(\"POST\", \"https://api.example.com/v1/books\", body)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"req.Header.Set(\"Content-Type\", \"application/json\")\n" +
+				"resp, err := http.DefaultClient.Do(req)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"defer resp.Body.Close()",
+		},
+	}
+	for _, test := range tests {
+		var body json.RawMessage
+		if test.body != "" {
+			body = json.RawMessage(test.body)
+		}
+		if got := test.sample(test.method, u, body).Code; got != test.want {
+			t.Errorf("%s: code =\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPythonLiteral(t *testing.T) {
+	tests := []struct {
+		example string
+		want    string
+	}{
+		{`true`, "True"},
+		{`false`, "False"},
+		{`null`, "None"},
+		{`"null"`, `"null"`},
+		{`"True"`, `"True"`},
+		{`1.5e3`, "1.5e3"},
+		{`{}`, "{}"},
+		{`[]`, "[]"},
+		{
+			`{"done":true,"tags":[null,"a"],"author":{}}`,
+			"{\n" +
+				"    \"done\": True,\n" +
+				"    \"tags\": [\n" +
+				"        None,\n" +
+				"        \"a\",\n" +
+				"    ],\n" +
+				"    \"author\": {},\n" +
+				"}",
+		},
+	}
+	for _, test := range tests {
+		if got := pythonLiteral(parseExample(json.RawMessage(test.example)), ""); got != test.want {
+			t.Errorf("pythonLiteral(%s) = %s, want %s", test.example, got, test.want)
+		}
+	}
+}
+
+func TestGrpcurlSample(t *testing.T) {
+	e := &Endpoint{FullMethod: "/shop.v1.Shop/GetBook"}
+	req := parseExample(json.RawMessage(`{"name":"Ender's Game"}`))
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{
+			"https://api.example.com",
+			"grpcurl \\\n  -d '{\n  \"name\": \"Ender'\\''s Game\"\n}' \\\n  api.example.com:443 shop.v1.Shop/GetBook",
+		},
+		{
+			"http://localhost:8080/api",
+			"grpcurl -plaintext \\\n  -d '{\n  \"name\": \"Ender'\\''s Game\"\n}' \\\n  localhost:8080 shop.v1.Shop/GetBook",
+		},
+		{
+			"http://localhost",
+			"grpcurl -plaintext \\\n  -d '{\n  \"name\": \"Ender'\\''s Game\"\n}' \\\n  localhost:80 shop.v1.Shop/GetBook",
+		},
+	}
+	for _, test := range tests {
+		if got := grpcurlSample(e, test.baseURL, req).Code; got != test.want {
+			t.Errorf("grpcurlSample(%q) =\n%s\nwant\n%s", test.baseURL, got, test.want)
+		}
+	}
+}
+
+func TestSampleURL(t *testing.T) {
+	// name is bound to "shelves/*/books/*" and parent to "*".
+	name := &PathVariable{
+		FieldPath: "book.name",
+		JSONPath:  "book.name",
+		Pattern:   "shelves/*/books/*",
+		Segments: []*PathSegment{
+			{Kind: SegmentLiteral, Value: "shelves"},
+			{Kind: SegmentWildcard, Value: "*"},
+			{Kind: SegmentLiteral, Value: "books"},
+			{Kind: SegmentWildcard, Value: "*"},
+		},
+	}
+	route := &Route{
+		Template: &PathTemplate{
+			Segments: []*PathSegment{
+				{Kind: SegmentLiteral, Value: "v1"},
+				{Kind: SegmentVariable, Variable: name},
+			},
+			Verb: "publish",
+		},
+		QueryParams: []*Param{
+			{Name: "tags", JSONPath: "tags", Repeated: true},
+			{Name: "pageSize", JSONPath: "pageSize"},
+		},
+	}
+	tests := []struct {
+		request string
+		want    string
+	}{
+		{`{}`, "/v1/shelves/abc123/books/abc123:publish"},
+		{`{"book":{"name":"shelves/1/books/a b"}}`, "/v1/shelves/1/books/a%20b:publish"},
+		// Values that do not match the pattern are replaced.
+		{`{"book":{"name":"books/1"}}`, "/v1/shelves/abc123/books/abc123:publish"},
+		{`{"book":{"name":"shelves/1/books/"}}`, "/v1/shelves/abc123/books/abc123:publish"},
+		{`{"tags":["a","b&c"],"pageSize":10}`, "/v1/shelves/abc123/books/abc123:publish?pageSize=10&tags=a&tags=b%26c"},
+		{`{"tags":[],"pageSize":null}`, "/v1/shelves/abc123/books/abc123:publish"},
+	}
+	for _, test := range tests {
+		req := parseExample(json.RawMessage(test.request))
+		if got := samplePath(route, req) + sampleQuery(route, req); got != test.want {
+			t.Errorf("URL for %s = %s, want %s", test.request, got, test.want)
+		}
+	}
+	root := &Route{Template: &PathTemplate{}}
+	if got := samplePath(root, exampleObject{}); got != "/" {
+		t.Errorf("URL of the root path = %s, want /", got)
+	}
+}
@@ -0,0 +1,74 @@
+package doc
+
+// RichText is a description parsed into blocks, such as paragraphs, lists and
+// code. It also contains the description rendered as Markdown and HTML, with
+// any markup in the comment that is not part of a block or span escaped.
+type RichText struct {
+	// Blocks is the list of blocks of the description.
+	Blocks []*Block `json:"blocks"`
+	// Markdown is the description rendered as Markdown.
+	Markdown string `json:"markdown"`
+	// HTML is the description rendered as HTML.
+	HTML string `json:"html"`
+}
+
+// Block is a block of a description.
+type Block struct {
+	// Kind is the kind of the block, one of the Block constants.
+	Kind string `json:"kind"`
+	// Text is the content of paragraphs and headings.
+	Text []*Inline `json:"text"`
+	// Code is the content of code blocks, with the common indentation
+	// removed.
+	Code string `json:"code"`
+	// Ordered is true if the block is a numbered list.
+	Ordered bool `json:"ordered"`
+	// Items is the list of items of lists.
+	Items []*ListItem `json:"items"`
+}
+
+// Kinds of blocks.
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockCode      = "code"
+	BlockList      = "list"
+)
+
+// ListItem is an item of a list.
+type ListItem struct {
+	// Number is the number of the item in numbered lists, or empty for
+	// bullet lists.
+	Number string `json:"number"`
+	// Blocks is the content of the item.
+	Blocks []*Block `json:"blocks"`
+}
+
+// Inline is a span of text in a paragraph or heading.
+type Inline struct {
+	// Kind is the kind of the span, one of the Inline constants.
+	Kind string `json:"kind"`
+	// Text is the text of the span.
+	Text string `json:"text"`
+	// URL is the target of links.
+	URL string `json:"url"`
+}
+
+// Kinds of inline spans.
+const (
+	InlineText = "text"
+	InlineCode = "code"
+	InlineLink = "link"
+)
+
+// join returns the rich text followed by the other rich text.
+func (t *RichText) join(other *RichText) *RichText {
+	blocks := make([]*Block, 0, len(t.Blocks)+len(other.Blocks))
+	blocks = append(blocks, t.Blocks...)
+	blocks = append(blocks, other.Blocks...)
+	return &RichText{
+		Blocks:   blocks,
+		Markdown: t.Markdown + "\n" + other.Markdown,
+		HTML:     t.HTML + other.HTML,
+	}
+}
@@ -1,6 +1,10 @@
 package doc
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
 
 // Type is the documentation for a data type.
 type Type interface {
@@ -17,14 +21,49 @@ type NamedType interface {
 
 // Message is the documentation for a message.
 type Message struct {
-	// Name is the name of the data type.
+	// Name is the name of the data type, scoped by the messages it is nested
+	// in.
 	Name string `json:"name"`
 	// Description is the description of the data type.
 	Description string `json:"description"`
+	// RichDescription is the description parsed into blocks, or nil if
+	// there is no description.
+	RichDescription *RichText `json:"rich_description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// Parent is the name of the message this message is nested in, or empty
+	// if it is not nested.
+	Parent string `json:"parent"`
 	// Fields is a list of fields in the data type.
 	Fields []*Field `json:"fields"`
+	// Oneofs is a list of groups of fields in Fields where at most one of
+	// the fields in each group can be set.
+	Oneofs []*Oneof `json:"oneofs"`
+	// Recursive is true if the message can contain itself, either directly
+	// or through other messages.
+	Recursive bool `json:"recursive"`
+	// ReservedRanges is the list of field numbers that are reserved.
+	ReservedRanges []*FieldRange `json:"reserved_ranges"`
+	// ReservedNames is the list of field names that are reserved.
+	ReservedNames []string `json:"reserved_names"`
+	// ExtensionRanges is the list of field numbers available for extensions.
+	ExtensionRanges []*FieldRange `json:"extension_ranges"`
+	// Example is an example of the message in JSON provided in its comment,
+	// or nil if there is none.
+	Example json.RawMessage `json:"example"`
 }
 
+// FieldRange is an inclusive range of field numbers.
+type FieldRange struct {
+	// Start is the first number in the range.
+	Start int32 `json:"start"`
+	// End is the last number in the range.
+	End int32 `json:"end"`
+}
+
+// MaxFieldNumber is the largest valid field number.
+const MaxFieldNumber = 1<<29 - 1
+
 // Field is the documentation for a field in a message.
 type Field struct {
 	// Name is the name of the field.
@@ -33,16 +72,98 @@ type Field struct {
 	GunkName string `json:"-"`
 	// Description is the description of the field.
 	Description string `json:"description"`
+	// Deprecation is the deprecation status.
+	Deprecation
 	// Type is the type of the field.
 	Type Type `json:"type"`
+	// Number is the field number used in the binary encoding.
+	Number int32 `json:"number"`
+	// Packed is true if the field is a repeated scalar that is encoded as a
+	// single packed value in the binary encoding.
+	Packed bool `json:"packed"`
+	// Oneof is the name of the oneof group the field belongs to, or empty if
+	// it does not belong to any.
+	Oneof string `json:"oneof"`
+	// Presence is whether an unset field can be distinguished from one set
+	// to the zero value. It is one of the Presence constants.
+	Presence string `json:"presence"`
+	// Behaviors is the list of google.api.field_behavior annotations of the
+	// field, such as BehaviorRequired.
+	Behaviors []string `json:"behaviors"`
+	// Example is an example of the value of the field in JSON provided in
+	// its comment, or nil if there is none.
+	Example json.RawMessage `json:"example"`
+}
+
+// HasBehavior returns true if the field is annotated with the behavior.
+func (f *Field) HasBehavior(behavior string) bool {
+	for _, b := range f.Behaviors {
+		if b == behavior {
+			return true
+		}
+	}
+	return false
+}
+
+// Common values of Field.Behaviors. Other google.api.field_behavior values are
+// recorded by their enum name as well.
+const (
+	// BehaviorRequired is the behavior of fields that must be set.
+	BehaviorRequired = "REQUIRED"
+	// BehaviorOutputOnly is the behavior of fields that are set by the server
+	// and ignored in requests.
+	BehaviorOutputOnly = "OUTPUT_ONLY"
+	// BehaviorInputOnly is the behavior of fields that are only provided in
+	// requests and never returned.
+	BehaviorInputOnly = "INPUT_ONLY"
+	// BehaviorImmutable is the behavior of fields that cannot be changed after
+	// they are set.
+	BehaviorImmutable = "IMMUTABLE"
+)
+
+const (
+	// PresenceImplicit is the presence of fields where the zero value is
+	// not distinguishable from an unset field.
+	PresenceImplicit = "implicit"
+	// PresenceExplicit is the presence of fields that track whether they
+	// are set, such as message fields, oneof members and proto3 optional
+	// fields.
+	PresenceExplicit = "explicit"
+	// PresenceRequired is the presence of proto2 required fields.
+	PresenceRequired = "required"
+	// PresenceRepeated is the presence of repeated and map fields, where an
+	// empty list is the same as an unset field.
+	PresenceRepeated = "repeated"
+)
+
+// Oneof is the documentation for a group of fields where at most one of the
+// fields can be set.
+type Oneof struct {
+	// Name is the name of the oneof.
+	Name string `json:"name"`
+	// Description is the description of the oneof.
+	Description string `json:"description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// Fields is the list of names of the fields in the oneof.
+	Fields []string `json:"fields"`
 }
 
 // Enum is the documentation for an enum.
 type Enum struct {
-	// Name is the name of the data type.
+	// Name is the name of the data type, scoped by the messages it is nested
+	// in.
 	Name string `json:"name"`
 	// Description is the description of the data type.
 	Description string `json:"description"`
+	// RichDescription is the description parsed into blocks, or nil if
+	// there is no description.
+	RichDescription *RichText `json:"rich_description"`
+	// Deprecation is the deprecation status.
+	Deprecation
+	// Parent is the name of the message this enum is nested in, or empty if
+	// it is not nested.
+	Parent string `json:"parent"`
 	// Values are the list of values for enum.
 	Values []*EnumVal `json:"values"`
 }
@@ -51,14 +172,33 @@ type Enum struct {
 type EnumVal struct {
 	// Value is the value of the enum.
 	Value string `json:"value"`
+	// DisplayName is the name of the value to display. It is the same as
+	// Value unless the prefix shared by the values of the enum is stripped.
+	DisplayName string `json:"display_name"`
+	// Number is the numeric value used in the binary encoding.
+	Number int32 `json:"number"`
+	// Aliases is the list of other values of the enum with the same number,
+	// which is only possible if the allow_alias option is set.
+	Aliases []string `json:"aliases"`
+	// Default is true if the value is the default value of the enum, such as
+	// the zero value in proto3.
+	Default bool `json:"default"`
 	// Description is the description of the enum value.
 	Description string `json:"description"`
+	// Deprecation is the deprecation status.
+	Deprecation
 }
 
 // Ref is a reference to a Message or Enum type.
 type Ref struct {
 	// Name is the fully qualified name of the referenced type.
 	Name string `json:"name"`
+	// Pos is the position of the declaration making the reference.
+	Pos diag.Pos `json:"-"`
+	// Recursive is true if the reference is part of a cycle, such that
+	// expanding the referenced type leads back to the type containing the
+	// reference.
+	Recursive bool `json:"recursive"`
 }
 
 // Map is the documentation for a map.
@@ -81,6 +221,33 @@ type Basic struct {
 	Name string `json:"name"`
 	// Example is an example of the value of the type.
 	Example string `json:"example"`
+	// Encoding is the representation of the type in proto3 JSON, or nil if
+	// it is not known.
+	Encoding *Encoding `json:"encoding"`
+}
+
+// Encoding is the representation of a basic type in proto3 JSON.
+type Encoding struct {
+	// Type is the JSON type of the value: "string", "number", "integer",
+	// "boolean", "object", "array" or "null". It is empty if any JSON value
+	// is allowed.
+	Type string `json:"type"`
+	// Format is the format of the value as named in JSON Schema and OpenAPI,
+	// such as "date-time", or empty if there is none.
+	Format string `json:"format"`
+	// ContentEncoding is the encoding of binary data in a string, such as
+	// "base64", or empty if the value is not binary.
+	ContentEncoding string `json:"content_encoding"`
+	// Nullable is true if null is also allowed.
+	Nullable bool `json:"nullable"`
+	// Enum is the list of strings allowed if only specific strings are.
+	Enum []string `json:"enum"`
+	// Description describes the representation for readers, such as
+	// "RFC 3339 date-time string".
+	Description string `json:"description"`
+	// Alternates is the list of other accepted representations, such as
+	// numbers for 64-bit integers and "NaN" for floats.
+	Alternates []*Encoding `json:"alternates"`
 }
 
 func (*Message) typ() string { return "message" }
@@ -0,0 +1,223 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// Suffixes of the names of the variants of messages created by
+// filterFieldBehavior.
+const (
+	inputSuffix  = "-Input"
+	outputSuffix = "-Output"
+)
+
+// filterFieldBehavior returns copies of the packages of a section where
+// OUTPUT_ONLY fields are removed from the requests of endpoints and INPUT_ONLY
+// fields are removed from their responses, including the fields of the
+// messages they reference. As named types are shared between requests and
+// responses, a referenced message that loses fields is replaced by a variant
+// named with the "-Input" or "-Output" suffix, such as "Book-Input", which is
+// added to the package of the message. Messages in packages outside of the
+// section are not filtered.
+//
+// Query parameters never include OUTPUT_ONLY fields, so they are not
+// filtered.
+func filterFieldBehavior(pkgs []*doc.Package) []*doc.Package {
+	newPkgs := make([]*doc.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		newPkg := *pkg
+		newPkg.Types = make(map[string]doc.Type, len(pkg.Types))
+		for name, t := range pkg.Types {
+			newPkg.Types[name] = t
+		}
+		newPkgs = append(newPkgs, &newPkg)
+	}
+	input := newBehaviorFilter(newPkgs, doc.BehaviorOutputOnly, inputSuffix)
+	output := newBehaviorFilter(newPkgs, doc.BehaviorInputOnly, outputSuffix)
+	for _, pkg := range newPkgs {
+		services := make([]*doc.Service, 0, len(pkg.Services))
+		for _, srv := range pkg.Services {
+			newSrv := *srv
+			newSrv.Endpoints = make([]*doc.Endpoint, 0, len(srv.Endpoints))
+			for _, e := range srv.Endpoints {
+				newE := *e
+				newE.Request = input.typ(e.Request)
+				newE.Response = output.typ(e.Response)
+				newSrv.Endpoints = append(newSrv.Endpoints, &newE)
+			}
+			services = append(services, &newSrv)
+		}
+		pkg.Services = services
+	}
+	return newPkgs
+}
+
+// behaviorFilter removes the fields with a behavior from types.
+type behaviorFilter struct {
+	pkgs     []*doc.Package
+	behavior string
+	suffix   string
+	// affected is the set of fully qualified names of messages that have
+	// fields with the behavior, either directly or through references.
+	affected map[string]bool
+	// variants is the set of fully qualified names of messages whose
+	// variant has been added to their package.
+	variants map[string]bool
+}
+
+// newBehaviorFilter returns a filter removing the fields with the behavior
+// from the types in the packages. Variants of messages are named with the
+// suffix.
+func newBehaviorFilter(pkgs []*doc.Package, behavior, suffix string) *behaviorFilter {
+	b := &behaviorFilter{
+		pkgs:     pkgs,
+		behavior: behavior,
+		suffix:   suffix,
+		affected: make(map[string]bool),
+		variants: make(map[string]bool),
+	}
+	msgs := make(map[string]*doc.Message)
+	for _, pkg := range pkgs {
+		for name, t := range pkg.Types {
+			if msg, ok := t.(*doc.Message); ok {
+				msgs[pkg.ID+"."+name] = msg
+			}
+		}
+	}
+	// Messages referencing affected messages are affected as well, which is
+	// repeated until no more messages are found to handle cycles.
+	for changed := true; changed; {
+		changed = false
+		for name, msg := range msgs {
+			if !b.affected[name] && b.hasAffectedField(msg) {
+				b.affected[name] = true
+				changed = true
+			}
+		}
+	}
+	return b
+}
+
+// hasAffectedField returns true if a field of the message has the behavior or
+// references an affected message.
+func (b *behaviorFilter) hasAffectedField(msg *doc.Message) bool {
+	for _, f := range msg.Fields {
+		if f.HasBehavior(b.behavior) {
+			return true
+		}
+		for _, ref := range typeRefs(f.Type) {
+			if b.affected[ref.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typ returns the type without the fields with the behavior. Types that are
+// not changed are returned as is.
+func (b *behaviorFilter) typ(t doc.Type) doc.Type {
+	switch t := t.(type) {
+	case *doc.Message:
+		return b.message(t)
+	case *doc.Ref:
+		if !b.affected[t.Name] {
+			return t
+		}
+		b.addVariant(t.Name)
+		newRef := *t
+		newRef.Name += b.suffix
+		return &newRef
+	case *doc.Array:
+		value := b.typ(t.Value)
+		if value == t.Value {
+			return t
+		}
+		return &doc.Array{Value: value}
+	case *doc.Map:
+		value := b.typ(t.Value)
+		if value == t.Value {
+			return t
+		}
+		return &doc.Map{Key: t.Key, Value: value}
+	}
+	return t
+}
+
+// message returns a copy of the message without the fields with the behavior,
+// also removing them from its oneofs, or the message itself if nothing is
+// removed from it or the messages it references.
+func (b *behaviorFilter) message(msg *doc.Message) *doc.Message {
+	fields := make([]*doc.Field, 0, len(msg.Fields))
+	removed := make(map[string]bool)
+	changed := false
+	for _, f := range msg.Fields {
+		if f.HasBehavior(b.behavior) {
+			removed[f.Name] = true
+			continue
+		}
+		typ := b.typ(f.Type)
+		if typ != f.Type {
+			newF := *f
+			newF.Type = typ
+			f = &newF
+			changed = true
+		}
+		fields = append(fields, f)
+	}
+	if !changed && len(removed) == 0 {
+		return msg
+	}
+	newMsg := *msg
+	newMsg.Fields = fields
+	newMsg.Oneofs = make([]*doc.Oneof, 0, len(msg.Oneofs))
+	for _, o := range msg.Oneofs {
+		newO := *o
+		newO.Fields = make([]string, 0, len(o.Fields))
+		for _, name := range o.Fields {
+			if !removed[name] {
+				newO.Fields = append(newO.Fields, name)
+			}
+		}
+		if len(newO.Fields) > 0 {
+			newMsg.Oneofs = append(newMsg.Oneofs, &newO)
+		}
+	}
+	return &newMsg
+}
+
+// addVariant adds the variant of the affected message with the fully
+// qualified name to its package if it is not added yet.
+func (b *behaviorFilter) addVariant(fullName string) {
+	if b.variants[fullName] {
+		return
+	}
+	b.variants[fullName] = true
+	for _, pkg := range b.pkgs {
+		name := strings.TrimPrefix(fullName, pkg.ID+".")
+		msg, ok := pkg.Types[name].(*doc.Message)
+		if name == fullName || !ok {
+			continue
+		}
+		variant := *b.message(msg)
+		variant.Name += b.suffix
+		pkg.Types[name+b.suffix] = &variant
+		return
+	}
+}
+
+// typeRefs returns the references in the type, including those used as array
+// values or map values.
+func typeRefs(t doc.Type) []*doc.Ref {
+	switch t := t.(type) {
+	case *doc.Ref:
+		return []*doc.Ref{t}
+	case *doc.Array:
+		return typeRefs(t.Value)
+	case *doc.Map:
+		return append(typeRefs(t.Key), typeRefs(t.Value)...)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// sectionPackages returns the packages as documented in the section by
+// applying the options of the section.
+func sectionPackages(pkgs []*doc.Package, sect config.Section) []*doc.Package {
+	newPkgs := make([]*doc.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		newPkgs = append(newPkgs, sectionPackage(pkg, sect))
+	}
+	if sect.FilterFieldBehavior {
+		newPkgs = filterFieldBehavior(newPkgs)
+	}
+	return newPkgs
+}
+
+// sectionPackage returns the package as documented in the section by applying
+// the options of the section that apply to each package on its own. Packages
+// may be shared by multiple sections so they are copied instead of modified.
+func sectionPackage(pkg *doc.Package, sect config.Section) *doc.Package {
+	if sect.ExcludeGRPC {
+		pkg = filterEndpoints(pkg, func(e *doc.Endpoint) bool {
+			return e.Method != ""
+		})
+	}
+	if sect.StripEnumPrefix {
+		pkg = stripEnumPrefix(pkg)
+	}
+	return pkg
+}
+
+// stripEnumPrefix returns a copy of the package where the display name of enum
+// values does not include the prefix shared by all values of the enum.
+func stripEnumPrefix(pkg *doc.Package) *doc.Package {
+	newPkg := *pkg
+	newPkg.Types = make(map[string]doc.Type, len(pkg.Types))
+	for name, t := range pkg.Types {
+		e, ok := t.(*doc.Enum)
+		if !ok {
+			newPkg.Types[name] = t
+			continue
+		}
+		prefix := enumPrefix(e)
+		newEnum := *e
+		newEnum.Values = make([]*doc.EnumVal, 0, len(e.Values))
+		for _, v := range e.Values {
+			newVal := *v
+			newVal.DisplayName = strings.TrimPrefix(v.Value, prefix)
+			newEnum.Values = append(newEnum.Values, &newVal)
+		}
+		newPkg.Types[name] = &newEnum
+	}
+	return &newPkg
+}
+
+// enumPrefix returns the name of the enum in UPPER_SNAKE_CASE followed by '_',
+// such as "ORDER_STATUS_" for OrderStatus, if every value of the enum starts
+// with it and is still a valid identifier after it is stripped. The name of a
+// nested enum is scoped by the messages it is nested in, such as
+// "ORDER_STATUS_" for Order.Status, or else is its own name. An empty string
+// is returned if the values do not share such a prefix.
+func enumPrefix(e *doc.Enum) string {
+	names := strings.Split(e.Name, ".")
+	for i := range names {
+		words := make([]string, 0, len(names)-i)
+		for _, name := range names[i:] {
+			words = append(words, upperSnakeCase(name))
+		}
+		if prefix := strings.Join(words, "_") + "_"; hasEnumPrefix(e, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// hasEnumPrefix returns true if every value of the enum starts with the prefix
+// and is still a valid identifier after it is stripped.
+func hasEnumPrefix(e *doc.Enum, prefix string) bool {
+	for _, v := range e.Values {
+		rest := strings.TrimPrefix(v.Value, prefix)
+		if rest == v.Value || rest == "" || ('0' <= rest[0] && rest[0] <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// upperSnakeCase converts the CamelCase name to UPPER_SNAKE_CASE, such as
+// "HTTPMethod" to "HTTP_METHOD".
+func upperSnakeCase(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if i > 0 && isUpper(c) {
+			prev := name[i-1]
+			nextLower := i+1 < len(name) && !isUpper(name[i+1]) && name[i+1] != '_'
+			if (prev != '_' && !isUpper(prev)) || (isUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteString(strings.ToUpper(string(c)))
+	}
+	return b.String()
+}
+
+// isUpper returns true if c is an upper case ASCII letter.
+func isUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+// filterEndpoints returns a copy of the package with only the endpoints that
+// keep returns true for.
+func filterEndpoints(pkg *doc.Package, keep func(e *doc.Endpoint) bool) *doc.Package {
+	newPkg := *pkg
+	newPkg.Services = make([]*doc.Service, 0, len(pkg.Services))
+	for _, srv := range pkg.Services {
+		newSrv := *srv
+		newSrv.Endpoints = make([]*doc.Endpoint, 0, len(srv.Endpoints))
+		for _, e := range srv.Endpoints {
+			if keep(e) {
+				newSrv.Endpoints = append(newSrv.Endpoints, e)
+			}
+		}
+		newPkg.Services = append(newPkg.Services, &newSrv)
+	}
+	return &newPkg
+}
@@ -0,0 +1,169 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// fieldNames returns the names of the fields of the message along with the
+// names of the types they reference.
+func fieldNames(t doc.Type) string {
+	msg, ok := t.(*doc.Message)
+	if !ok {
+		return "not a message"
+	}
+	names := make([]string, 0, len(msg.Fields))
+	for _, f := range msg.Fields {
+		name := f.Name
+		if refs := typeRefs(f.Type); len(refs) > 0 {
+			name += ":" + refs[0].Name
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestFilterFieldBehaviorNested(t *testing.T) {
+	book := &doc.Message{
+		Name: "Book",
+		Fields: []*doc.Field{
+			{Name: "name", Type: &doc.Basic{Name: "String"}},
+			{Name: "createTime", Type: &doc.Basic{Name: "Timestamp"}, Behaviors: []string{doc.BehaviorOutputOnly}},
+			{Name: "token", Type: &doc.Basic{Name: "String"}, Behaviors: []string{doc.BehaviorInputOnly}, Oneof: "secret"},
+			{Name: "related", Type: &doc.Array{Value: &doc.Ref{Name: "shop.Book", Recursive: true}}},
+			{Name: "author", Type: &doc.Ref{Name: "shop.Author"}},
+		},
+		Oneofs: []*doc.Oneof{{Name: "secret", Fields: []string{"token"}}},
+	}
+	author := &doc.Message{
+		Name:   "Author",
+		Fields: []*doc.Field{{Name: "name", Type: &doc.Basic{Name: "String"}}},
+	}
+	shelf := &doc.Message{
+		Name:   "Shelf",
+		Fields: []*doc.Field{{Name: "books", Type: &doc.Map{Key: &doc.Basic{Name: "String"}, Value: &doc.Ref{Name: "shop.Book"}}}},
+	}
+	create := &doc.Endpoint{
+		Name: "CreateBook",
+		Request: &doc.Message{
+			Name: "CreateBookRequest",
+			Fields: []*doc.Field{
+				{Name: "parent", Type: &doc.Basic{Name: "String"}},
+				{Name: "book", Type: &doc.Ref{Name: "shop.Book"}},
+			},
+		},
+		Response: &doc.Ref{Name: "shop.Book"},
+	}
+	getShelf := &doc.Endpoint{
+		Name:     "GetShelf",
+		Request:  &doc.Message{Name: "GetShelfRequest"},
+		Response: &doc.Ref{Name: "shop.Shelf"},
+	}
+	pkg := &doc.Package{
+		ID: "shop",
+		Types: map[string]doc.Type{
+			"Book":   book,
+			"Author": author,
+			"Shelf":  shelf,
+		},
+		Services: []*doc.Service{{Name: "Shop", Endpoints: []*doc.Endpoint{create, getShelf}}},
+	}
+	got := sectionPackages([]*doc.Package{pkg}, config.Section{FilterFieldBehavior: true})[0]
+
+	endpoints := got.Services[0].Endpoints
+	if ref := endpoints[0].Response.(*doc.Ref); ref.Name != "shop.Book-Output" {
+		t.Errorf("response of CreateBook = %q, want shop.Book-Output", ref.Name)
+	}
+	if ref := endpoints[1].Response.(*doc.Ref); ref.Name != "shop.Shelf-Output" {
+		t.Errorf("response of GetShelf = %q, want shop.Shelf-Output", ref.Name)
+	}
+	tests := []struct {
+		name string
+		typ  doc.Type
+		want string
+	}{
+		{"request", endpoints[0].Request, "parent,book:shop.Book-Input"},
+		{"Book-Input", got.Types["Book-Input"], "name,token,related:shop.Book-Input,author:shop.Author"},
+		{"Book-Output", got.Types["Book-Output"], "name,createTime,related:shop.Book-Output,author:shop.Author"},
+		{"Shelf-Output", got.Types["Shelf-Output"], "books:shop.Book-Output"},
+		{"Book", got.Types["Book"], "name,createTime,token,related:shop.Book,author:shop.Author"},
+	}
+	for _, test := range tests {
+		if got := fieldNames(test.typ); got != test.want {
+			t.Errorf("fields of %s = %q, want %q", test.name, got, test.want)
+		}
+	}
+	if _, ok := got.Types["Author-Input"]; ok {
+		t.Errorf("variant of Author is added although none of its fields is removed")
+	}
+	if _, ok := got.Types["Shelf-Input"]; ok {
+		t.Errorf("variant of Shelf is added although it is only used in responses")
+	}
+	if oneofs := got.Types["Book-Output"].(*doc.Message).Oneofs; len(oneofs) != 0 {
+		t.Errorf("oneofs of Book-Output = %v, want the emptied oneof removed", oneofs)
+	}
+	if variant := got.Types["Book-Input"].(*doc.Message); variant.Name != "Book-Input" {
+		t.Errorf("name of Book-Input = %q", variant.Name)
+	}
+	if fieldNames(pkg.Types["Book"]) != fieldNames(book) || len(pkg.Types) != 3 || pkg.Services[0].Endpoints[0] != create {
+		t.Errorf("original package is modified")
+	}
+	if ref := create.Request.(*doc.Message).Fields[1].Type.(*doc.Ref); ref.Name != "shop.Book" {
+		t.Errorf("original request is modified")
+	}
+}
+
+// enumValues returns an enum with the name and values.
+func enumValues(name string, values ...string) *doc.Enum {
+	e := &doc.Enum{Name: name}
+	for _, v := range values {
+		e.Values = append(e.Values, &doc.EnumVal{Value: v})
+	}
+	return e
+}
+
+func TestEnumPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"Status", nil, "STATUS_"},
+		{"Status", []string{"STATUS_UNSPECIFIED", "STATUS_ACTIVE"}, "STATUS_"},
+		{"OrderStatus", []string{"ORDER_STATUS_UNSPECIFIED", "ORDER_STATUS_PAID"}, "ORDER_STATUS_"},
+		{"HTTPMethod", []string{"HTTP_METHOD_GET", "HTTP_METHOD_POST"}, "HTTP_METHOD_"},
+		// Nested enums are prefixed with their scoped name or their own name.
+		{"Order.Status", []string{"ORDER_STATUS_OPEN", "ORDER_STATUS_CLOSED"}, "ORDER_STATUS_"},
+		{"Order.Status", []string{"STATUS_OPEN", "STATUS_CLOSED"}, "STATUS_"},
+		{"Order.Status", []string{"ORDER_STATUS_OPEN", "STATUS_CLOSED"}, ""},
+		// Only the name of the enum is stripped from the values.
+		{"OrderStatus", []string{"ORDER_STATUS_PAYMENT_PENDING", "ORDER_STATUS_PAYMENT_FAILED"}, "ORDER_STATUS_"},
+		{"Color", []string{"RED_DARK", "RED_LIGHT"}, ""},
+		{"Status", []string{"STATUS_ACTIVE", "ACTIVE"}, ""},
+		{"Status", []string{"UNKNOWN", "ACTIVE"}, ""},
+		// Stripping may not leave an empty or numeric name.
+		{"Size", []string{"SIZE_", "SIZE_SMALL"}, ""},
+		{"Size", []string{"SIZE_1", "SIZE_2"}, ""},
+	}
+	for _, test := range tests {
+		if got := enumPrefix(enumValues(test.name, test.values...)); got != test.want {
+			t.Errorf("enumPrefix(%s %v) = %q, want %q", test.name, test.values, got, test.want)
+		}
+	}
+}
+
+func TestStripEnumPrefix(t *testing.T) {
+	status := enumValues("Status", "STATUS_UNSPECIFIED", "STATUS_ACTIVE")
+	pkg := &doc.Package{ID: "shop", Types: map[string]doc.Type{"Status": status}}
+	got := sectionPackages([]*doc.Package{pkg}, config.Section{StripEnumPrefix: true})[0]
+	values := got.Types["Status"].(*doc.Enum).Values
+	if values[0].DisplayName != "UNSPECIFIED" || values[1].DisplayName != "ACTIVE" || values[1].Value != "STATUS_ACTIVE" {
+		t.Errorf("values = %+v %+v, want display names without the prefix", values[0], values[1])
+	}
+	if status.Values[0].DisplayName != "" {
+		t.Errorf("original enum is modified")
+	}
+}
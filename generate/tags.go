@@ -2,15 +2,19 @@ package generate
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 )
 
 // Tags combines the packages provided into tags based on the specified config.
-// Packages that are not specified will be placed in the `default` tag.
-func Tags(cfg *config.Config, pkgs []*doc.Package) (map[string]*doc.Tag, error) {
+// Packages that are not specified will be placed in the `default` tag. Types
+// that are not used by the endpoints documented in any tag are pruned after
+// the options of the sections are applied. Problems found are reported to d.
+func Tags(cfg *config.Config, pkgs []*doc.Package, d *diag.List) (map[string]*doc.Tag, error) {
 	usedPkgs := make(map[string]bool)
 	tags := make(map[string]*doc.Tag, len(cfg.Sections)+1)
 	var defaultSection config.Section
@@ -20,21 +24,21 @@ func Tags(cfg *config.Config, pkgs []*doc.Package) (map[string]*doc.Tag, error)
 			defaultSection = sect
 			continue
 		}
-		tag := &doc.Tag{
-			Name:     sect.DisplayName,
-			Preamble: sect.PreambleContent,
-			Weight:   sect.Weight,
-			Packages: make([]*doc.Package, 0, len(sect.Packages)),
-		}
+		sectPkgs := make([]*doc.Package, 0, len(sect.Packages))
 		for _, pkgName := range sect.Packages {
 			pkg, err := findPkg(pkgs, pkgName, tagName)
 			if err != nil {
 				return nil, err
 			}
 			usedPkgs[pkg.ID] = true
-			tag.Packages = append(tag.Packages, pkg)
+			sectPkgs = append(sectPkgs, pkg)
+		}
+		tags[tagName] = &doc.Tag{
+			Name:     sect.DisplayName,
+			Preamble: sect.PreambleContent,
+			Weight:   sect.Weight,
+			Packages: sectionPackages(sectPkgs, sect),
 		}
-		tags[tagName] = tag
 	}
 
 	defaultPkgs := make([]*doc.Package, 0)
@@ -47,11 +51,27 @@ func Tags(cfg *config.Config, pkgs []*doc.Package) (map[string]*doc.Tag, error)
 		Name:     defaultSection.DisplayName,
 		Preamble: defaultSection.PreambleContent,
 		Weight:   defaultSection.Weight,
-		Packages: defaultPkgs,
+		Packages: sectionPackages(defaultPkgs, defaultSection),
 	}
+	pruneTypes(tags, d)
 	return tags, nil
 }
 
+// pruneTypes prunes the types of the packages in the tags. Types used in one
+// tag are kept in every tag as references may link across tags.
+func pruneTypes(tags map[string]*doc.Tag, d *diag.List) {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pkgs []*doc.Package
+	for _, name := range names {
+		pkgs = append(pkgs, tags[name].Packages...)
+	}
+	doc.PruneTypes(pkgs, d)
+}
+
 func findPkg(pkgs []*doc.Package, pkgName, tagName string) (*doc.Package, error) {
 	// Priority:
 	// - Match on exact ID
@@ -0,0 +1,83 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// refMessage returns a message with a field referencing the named type.
+func refMessage(name, ref string) *doc.Message {
+	return &doc.Message{
+		Name:   name,
+		Fields: []*doc.Field{{Name: "value", Type: &doc.Ref{Name: ref}}},
+	}
+}
+
+func TestTagsPruneFilteredEndpoints(t *testing.T) {
+	shop := &doc.Package{
+		ID: "shop",
+		Types: map[string]doc.Type{
+			"Item":    &doc.Message{Name: "Item"},
+			"Stream":  &doc.Message{Name: "Stream"},
+			"Unknown": &doc.Message{Name: "Unknown"},
+		},
+		Services: []*doc.Service{{
+			Name: "Shop",
+			Endpoints: []*doc.Endpoint{
+				{
+					Name:     "GetItem",
+					Method:   "GET",
+					Request:  &doc.Message{Name: "GetItemRequest"},
+					Response: refMessage("GetItemResponse", "shop.Item"),
+				},
+				{
+					Name:     "Watch",
+					Request:  &doc.Message{Name: "WatchRequest"},
+					Response: refMessage("WatchResponse", "shop.Stream"),
+				},
+			},
+		}},
+	}
+	// The default section documents the type used by a gRPC-only endpoint
+	// of another package.
+	admin := &doc.Package{
+		ID: "admin",
+		Types: map[string]doc.Type{
+			"Report": &doc.Message{Name: "Report"},
+		},
+		Services: []*doc.Service{{
+			Name: "Admin",
+			Endpoints: []*doc.Endpoint{{
+				Name:     "Export",
+				Request:  &doc.Message{Name: "ExportRequest"},
+				Response: refMessage("ExportResponse", "admin.Report"),
+			}},
+		}},
+	}
+	cfg := &config.Config{
+		Sections: map[string]config.Section{
+			"shop": {Packages: []string{"shop"}, ExcludeGRPC: true},
+		},
+	}
+	var d diag.List
+	tags, err := Tags(cfg, []*doc.Package{shop, admin}, &d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := tags["shop"].Packages[0]
+	if _, ok := got.Types["Item"]; !ok || len(got.Types) != 1 {
+		t.Errorf("types of shop = %v, want only Item", got.Types)
+	}
+	if len(shop.Types) != 3 {
+		t.Errorf("types of the original package are modified")
+	}
+	if _, ok := tags["default"].Packages[0].Types["Report"]; !ok {
+		t.Errorf("type used by the default section is pruned")
+	}
+	if err := d.Err(); err != nil || len(d.Warnings()) != 0 {
+		t.Errorf("diagnostics = %v %v, want none", err, d.Warnings())
+	}
+}
@@ -1,16 +1,18 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
 	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"github.com/chanbakjsd/protoc-gen-doc/generate"
 	"github.com/chanbakjsd/protoc-gen-doc/proto"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 func main() {
@@ -18,43 +20,118 @@ func main() {
 }
 
 func run(p *protogen.Plugin) error {
-	cfg, err := readConfig(p.Request.GetParameter())
+	// Presence of proto3 optional fields is documented.
+	p.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	params, err := parseParams(p.Request.GetParameter())
 	if err != nil {
 		return err
 	}
+	cfg, err := config.LoadFile(params.config)
+	if err != nil {
+		return err
+	}
+	scalars := make(proto.Scalars, len(cfg.Scalars))
+	for _, s := range cfg.Scalars {
+		scalars[s.Message] = doc.Basic{
+			Name:    s.DisplayName,
+			Example: s.Example,
+			Encoding: &doc.Encoding{
+				Type:        s.JSONType,
+				Format:      s.Format,
+				Description: s.Description,
+			},
+		}
+	}
 	sort.Slice(p.Files, func(i int, j int) bool {
 		return p.Files[i].Proto.GetName() < p.Files[j].Proto.GetName()
 	})
+	var diags diag.List
 	pkgs := make([]*doc.Package, 0, len(p.Files))
+	genPkgIDs := make(map[string]bool)
+	msgs := make(proto.Messages)
 	for _, f := range p.Files {
-		pkg := proto.ConvertFile(f)
+		pkg := proto.ConvertFile(f, scalars, msgs, &diags)
+		// Types of other files may be referenced but only generated files
+		// name the package and document services.
+		if f.Generate {
+			genPkgIDs[pkg.ID] = true
+		} else {
+			pkg.Name = ""
+			pkg.Services = nil
+		}
 		pkgs = append(pkgs, pkg)
 	}
-	doc.PruneTypes(pkgs)
+	pkgs = doc.MergePackages(pkgs, &diags)
+	doc.MarkRecursive(pkgs)
 	genPkgs := make([]*doc.Package, 0, len(pkgs))
-	for i, f := range p.Files {
-		if f.Generate {
-			genPkgs = append(genPkgs, pkgs[i])
+	for _, pkg := range pkgs {
+		if genPkgIDs[pkg.ID] {
+			genPkgs = append(genPkgs, pkg)
 		}
 	}
-	doc.PruneTypes(genPkgs)
-	tags, err := generate.Tags(cfg, genPkgs)
+	var fixtures map[string]*doc.Fixture
+	if cfg.ExamplesDir != "" {
+		fixtures = proto.LoadFixtures(cfg.ExamplesDir, p.Files, &diags)
+	}
+	doc.AddExamples(pkgs, fixtures)
+	doc.AddSamples(pkgs, cfg.BaseURL)
+	tags, err := generate.Tags(cfg, genPkgs, &diags)
 	if err != nil {
 		return err
 	}
-	for name, tag := range tags {
-		f := p.NewGeneratedFile(name+".json", "")
-		if err := json.NewEncoder(f).Encode(tag); err != nil {
+	warned := len(diags.Warnings())
+	for _, w := range diags.Warnings() {
+		fmt.Fprintln(os.Stderr, w)
+	}
+	if err := diags.Err(); err != nil {
+		return err
+	}
+	for _, format := range params.formats {
+		if err := outputs[format](p, cfg, tags, &diags); err != nil {
 			return err
 		}
 	}
-	return nil
+	// Outputs may report problems that only affect their format.
+	for _, w := range diags.Warnings()[warned:] {
+		fmt.Fprintln(os.Stderr, w)
+	}
+	return diags.Err()
+}
+
+// params are the parameters passed to the plugin.
+type params struct {
+	// config is the location of the configuration file.
+	config string
+	// formats is the list of output formats to write.
+	formats []string
 }
 
-func readConfig(param string) (*config.Config, error) {
-	if !strings.HasPrefix(param, "config=") {
-		return nil, fmt.Errorf("config location not provided, pass param in the form of 'config=...'")
+// parseParams parses the comma-separated parameter string in the form of
+// "config=...,format=...". format may be repeated and defaults to "json".
+func parseParams(param string) (params, error) {
+	var res params
+	for _, kv := range strings.Split(param, ",") {
+		if kv == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		default:
+			return params{}, fmt.Errorf("unknown param %q", k)
+		case "config":
+			res.config = v
+		case "format":
+			if _, ok := outputs[v]; !ok {
+				return params{}, fmt.Errorf("unknown output format %q", v)
+			}
+			res.formats = append(res.formats, v)
+		}
+	}
+	if res.config == "" {
+		return params{}, fmt.Errorf("config location not provided, pass param in the form of 'config=...'")
+	}
+	if len(res.formats) == 0 {
+		res.formats = []string{"json"}
 	}
-	file := strings.TrimPrefix(param, "config=")
-	return config.LoadFile(file)
+	return res, nil
 }
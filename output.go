@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"github.com/chanbakjsd/protoc-gen-doc/render"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// outputs maps the name of each output format to the function writing it.
+// Problems found in the tags are reported to d.
+var outputs = map[string]func(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error{
+	"json":         writeJSON,
+	"markdown":     writeMarkdown,
+	"html":         writeHTML,
+	"openapi":      writeOpenAPI,
+	"jsonschema":   writeJSONSchema,
+	"deprecations": writeDeprecations,
+}
+
+// writeJSON writes each tag as "<section>.json".
+func writeJSON(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	for name, tag := range tags {
+		f := p.NewGeneratedFile(name+".json", "")
+		if err := json.NewEncoder(f).Encode(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes each tag as "<section>.md".
+func writeMarkdown(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	idx := render.NewIndex(tags)
+	for name, tag := range tags {
+		f := p.NewGeneratedFile(name+".md", "")
+		if err := render.Markdown(f, idx, name, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHTML writes a static HTML site with a page for each tag.
+func writeHTML(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	site := render.NewHTMLSite(render.NewIndex(tags), tags)
+	for _, page := range site.Pages() {
+		f := p.NewGeneratedFile(page, "")
+		if err := site.Render(f, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOpenAPI writes all tags as a single "openapi.json" document.
+func writeOpenAPI(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	f := p.NewGeneratedFile("openapi.json", "")
+	return render.OpenAPI(f, tags, cfg.Version, d)
+}
+
+// writeJSONSchema writes a JSON Schema document for every named type in
+// "jsonschema/".
+func writeJSONSchema(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	written := make(map[string]bool)
+	for _, tag := range tags {
+		for _, pkg := range tag.Packages {
+			for name, typ := range pkg.Types {
+				fullName := pkg.ID + "." + name
+				if written[fullName] {
+					continue
+				}
+				written[fullName] = true
+				f := p.NewGeneratedFile("jsonschema/"+render.JSONSchemaFile(fullName), "")
+				enc := json.NewEncoder(f)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(render.JSONSchema(fullName, typ)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeDeprecations writes the deprecated elements of all tags as a single
+// "deprecations.md" report.
+func writeDeprecations(p *protogen.Plugin, cfg *config.Config, tags map[string]*doc.Tag, d *diag.List) error {
+	f := p.NewGeneratedFile("deprecations.md", "")
+	return render.DeprecationReport(f, tags)
+}
@@ -0,0 +1,612 @@
+package proto
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// richHeadingLevel is the level of headings in descriptions, which are placed
+// below the headings of endpoints and types.
+const richHeadingLevel = 5
+
+// parseRichText parses the comment into blocks and renders it as Markdown and
+// HTML. It follows a subset of CommonMark: paragraphs, headings such as
+// "# Title", bullet and numbered lists, fenced code blocks, code spans and
+// links such as "[text](url)", "[text]" with a "[text]: url" definition, "<url>"
+// or bare URLs. As in Go doc comments,
+// indented lines that do not continue a paragraph or a list are code blocks.
+// Any other markup is escaped. It returns nil if the comment is empty.
+func parseRichText(text string) *doc.RichText {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = expandTabs(l)
+	}
+	lines, links := linkDefinitions(lines)
+	blocks := parseBlocks(lines, links)
+	return &doc.RichText{
+		Blocks:   blocks,
+		Markdown: markdownBlocks(blocks, false) + "\n",
+		HTML:     htmlBlocks(blocks, false),
+	}
+}
+
+// blockParser is the state of parsing lines into blocks.
+type blockParser struct {
+	lines []string
+	i     int
+	// links maps the normalized labels of link reference definitions to
+	// their URL.
+	links  map[string]string
+	blocks []*doc.Block
+}
+
+// parseBlocks parses the lines into blocks, resolving references to the
+// links.
+func parseBlocks(lines []string, links map[string]string) []*doc.Block {
+	p := &blockParser{lines: lines, links: links}
+	for p.i < len(p.lines) {
+		p.block()
+	}
+	return p.blocks
+}
+
+// block parses the block starting at the current line.
+func (p *blockParser) block() {
+	l := p.lines[p.i]
+	if isBlank(l) {
+		p.i++
+		return
+	}
+	if text, ok := parseHeading(l); ok {
+		p.blocks = append(p.blocks, &doc.Block{Kind: doc.BlockHeading, Text: parseInlines(text, p.links)})
+		p.i++
+		return
+	}
+	if f, ok := parseFence(l); ok {
+		p.fencedCode(f)
+		return
+	}
+	if _, ok := parseListItem(l); ok {
+		p.list()
+		return
+	}
+	if indentation(l) > 0 {
+		p.indentedCode()
+		return
+	}
+	p.paragraph()
+}
+
+// paragraph parses a paragraph, which continues until a blank line or a line
+// starting another block.
+func (p *blockParser) paragraph() {
+	var lines []string
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if isBlank(l) || (len(lines) > 0 && interruptsParagraph(l)) {
+			break
+		}
+		lines = append(lines, strings.TrimSpace(l))
+		p.i++
+	}
+	p.blocks = append(p.blocks, &doc.Block{
+		Kind: doc.BlockParagraph,
+		Text: parseInlines(strings.Join(lines, "\n"), p.links),
+	})
+}
+
+// interruptsParagraph returns true if the line starts a block even if it
+// directly follows a line of a paragraph. Numbered lists only do so if they
+// start at 1, so that numbers wrapped to the start of a line stay in the
+// paragraph.
+func interruptsParagraph(l string) bool {
+	if _, ok := parseHeading(l); ok {
+		return true
+	}
+	if _, ok := parseFence(l); ok {
+		return true
+	}
+	item, ok := parseListItem(l)
+	return ok && (item.number == "" || item.number == "1")
+}
+
+// indentedCode parses a code block of indented lines.
+func (p *blockParser) indentedCode() {
+	start, end := p.i, p.i
+	for ; p.i < len(p.lines); p.i++ {
+		l := p.lines[p.i]
+		if isBlank(l) {
+			continue
+		}
+		if indentation(l) == 0 {
+			break
+		}
+		end = p.i + 1
+	}
+	p.i = end
+	p.blocks = append(p.blocks, &doc.Block{
+		Kind: doc.BlockCode,
+		Code: dedent(strings.Join(p.lines[start:end], "\n")),
+	})
+}
+
+// fence is the line opening a fenced code block.
+type fence struct {
+	// marker is the run of backticks or tildes opening the block.
+	marker string
+	indent int
+}
+
+// parseFence parses the line as the opening of a fenced code block, such as
+// "```json".
+func parseFence(l string) (fence, bool) {
+	indent := indentation(l)
+	rest := l[indent:]
+	if indent > 3 || !(strings.HasPrefix(rest, "```") || strings.HasPrefix(rest, "~~~")) {
+		return fence{}, false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	// The info string of backtick fences may not contain backticks, so that
+	// they are not confused with code spans.
+	if rest[0] == '`' && strings.Contains(rest[n:], "`") {
+		return fence{}, false
+	}
+	return fence{marker: rest[:n], indent: indent}, true
+}
+
+// fencedCode parses the fenced code block opened by f at the current line,
+// which continues until a closing fence or the end of the comment.
+func (p *blockParser) fencedCode(f fence) {
+	p.i++
+	var lines []string
+	for ; p.i < len(p.lines); p.i++ {
+		l := p.lines[p.i]
+		closing := strings.TrimSpace(l)
+		if indentation(l) <= 3 && strings.HasPrefix(closing, f.marker) && strings.Trim(closing, f.marker[:1]) == "" {
+			p.i++
+			break
+		}
+		n := indentation(l)
+		if n > f.indent {
+			n = f.indent
+		}
+		lines = append(lines, l[n:])
+	}
+	p.blocks = append(p.blocks, &doc.Block{
+		Kind: doc.BlockCode,
+		Code: strings.Join(lines, "\n"),
+	})
+}
+
+// listItem is the line starting an item of a list.
+type listItem struct {
+	// number is the number of items of numbered lists, or empty for bullet
+	// lists.
+	number string
+	// text is the text following the marker.
+	text string
+	// contentIndent is the indentation of lines continuing the item, which
+	// is the column its text starts at.
+	contentIndent int
+}
+
+// parseListItem parses the line as the start of a list item, which starts
+// with a bullet such as "- " or a number such as "1. " or "1) ".
+func parseListItem(l string) (listItem, bool) {
+	indent := indentation(l)
+	rest := l[indent:]
+	var marker, number string
+	for _, bullet := range []string{"-", "*", "+", "•"} {
+		if strings.HasPrefix(rest, bullet) {
+			marker = bullet
+			break
+		}
+	}
+	if marker == "" {
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if digits == 0 || digits > 9 || digits == len(rest) || (rest[digits] != '.' && rest[digits] != ')') {
+			return listItem{}, false
+		}
+		marker, number = rest[:digits+1], rest[:digits]
+	}
+	rest = rest[len(marker):]
+	width := indent + utf8.RuneCountInString(marker)
+	if rest == "" {
+		return listItem{number: number, contentIndent: width + 1}, true
+	}
+	if rest[0] != ' ' {
+		return listItem{}, false
+	}
+	// Text indented further than that starts with a code block.
+	spaces := len(rest) - len(strings.TrimLeft(rest, " "))
+	if spaces > 4 {
+		spaces = 1
+	}
+	return listItem{number: number, text: rest[spaces:], contentIndent: width + spaces}, true
+}
+
+// list parses a list, whose items are all numbered or all bulleted.
+func (p *blockParser) list() {
+	first, _ := parseListItem(p.lines[p.i])
+	list := &doc.Block{Kind: doc.BlockList, Ordered: first.number != ""}
+	for {
+		item, _ := parseListItem(p.lines[p.i])
+		p.i++
+		list.Items = append(list.Items, &doc.ListItem{
+			Number: item.number,
+			Blocks: parseBlocks(p.itemLines(item), p.links),
+		})
+		next := p.nextNonBlank()
+		if next == len(p.lines) {
+			break
+		}
+		nextItem, ok := parseListItem(p.lines[next])
+		if !ok || (nextItem.number != "") != list.Ordered {
+			break
+		}
+		p.i = next
+	}
+	p.blocks = append(p.blocks, list)
+}
+
+// itemLines returns the lines of the content of the item starting at the
+// previous line, without the indentation of the item.
+func (p *blockParser) itemLines(item listItem) []string {
+	lines := []string{item.text}
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		switch {
+		case isBlank(l):
+			// Blank lines are part of the item if it continues after them.
+			next := p.nextNonBlank()
+			if next == len(p.lines) || indentation(p.lines[next]) < item.contentIndent {
+				return lines
+			}
+			for ; p.i < next; p.i++ {
+				lines = append(lines, "")
+			}
+		case indentation(l) >= item.contentIndent:
+			lines = append(lines, l[item.contentIndent:])
+			p.i++
+		case interruptsParagraph(l):
+			return lines
+		default:
+			if _, ok := parseListItem(l); ok {
+				return lines
+			}
+			// Lines that are not indented enough continue the paragraph.
+			lines = append(lines, strings.TrimLeft(l, " "))
+			p.i++
+		}
+	}
+	return lines
+}
+
+// nextNonBlank returns the index of the next line that is not blank, starting
+// at the current line.
+func (p *blockParser) nextNonBlank() int {
+	i := p.i
+	for i < len(p.lines) && isBlank(p.lines[i]) {
+		i++
+	}
+	return i
+}
+
+// parseHeading parses the line as a heading, such as "# Title".
+func parseHeading(l string) (string, bool) {
+	indent := indentation(l)
+	rest := l[indent:]
+	level := len(rest) - len(strings.TrimLeft(rest, "#"))
+	if indent > 3 || level == 0 || level > 6 {
+		return "", false
+	}
+	rest = rest[level:]
+	if rest != "" && rest[0] != ' ' {
+		return "", false
+	}
+	// Remove the optional closing sequence, such as in "# Title #".
+	text := strings.TrimSpace(rest)
+	if closed := strings.TrimRight(text, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		text = strings.TrimSpace(closed)
+	}
+	return text, true
+}
+
+// parseInlines parses the text of a paragraph or heading into spans, resolving
+// references to the links. Line breaks are turned into spaces.
+func parseInlines(s string, links map[string]string) []*doc.Inline {
+	var inlines []*doc.Inline
+	var text strings.Builder
+	add := func(kind, s, url string) {
+		if text.Len() > 0 {
+			inlines = append(inlines, &doc.Inline{Kind: doc.InlineText, Text: text.String()})
+			text.Reset()
+		}
+		if kind != "" {
+			inlines = append(inlines, &doc.Inline{Kind: kind, Text: s, URL: url})
+		}
+	}
+	s = strings.ReplaceAll(s, "\n", " ")
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		if rest[0] == '\\' && len(rest) > 1 && strings.IndexByte(asciiPunctuation, rest[1]) >= 0 {
+			text.WriteByte(rest[1])
+			i += 2
+			continue
+		}
+		if rest[0] == '`' {
+			code, n := parseCodeSpan(rest)
+			if code != "" {
+				add(doc.InlineCode, code, "")
+			} else {
+				text.WriteString(rest[:n])
+			}
+			i += n
+			continue
+		}
+		if label, u, n := parseLink(rest, links); n > 0 {
+			add(doc.InlineLink, label, u)
+			i += n
+			continue
+		}
+		if i == 0 || !isAlphanumeric(s[i-1]) {
+			if u := parseBareURL(rest); u != "" {
+				add(doc.InlineLink, u, u)
+				i += len(u)
+				continue
+			}
+		}
+		text.WriteByte(rest[0])
+		i++
+	}
+	add("", "", "")
+	return inlines
+}
+
+// asciiPunctuation is the characters that may be escaped with a backslash.
+const asciiPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// parseCodeSpan parses the code span at the start of s, which starts with a
+// backtick. It returns the code and the length of the span, or an empty string
+// and the length of the run of backticks if the span is not closed.
+func parseCodeSpan(s string) (string, int) {
+	n := len(s) - len(strings.TrimLeft(s, "`"))
+	for i := n; i < len(s); {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			break
+		}
+		i += j
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			code := s[n:i]
+			// One space is stripped from both sides so that code starting
+			// or ending with a backtick can be written.
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			return code, i + run
+		}
+		i += run
+	}
+	return "", n
+}
+
+// parseLink parses the link at the start of s, such as "[text](url)",
+// "[text][label]", "[label]" or "<url>", where labels are resolved with links.
+// It returns the text and the URL of the link along with its length, or a
+// length of 0 if there is no link or its URL is not safe.
+func parseLink(s string, links map[string]string) (text, u string, n int) {
+	switch s[0] {
+	case '<':
+		end := strings.IndexAny(s[1:], "> <") + 1
+		if end == 0 || s[end] != '>' {
+			return "", "", 0
+		}
+		u = s[1:end]
+		if !strings.Contains(u, ":") || !safeURL(u) {
+			return "", "", 0
+		}
+		return u, u, end + 1
+	case '[':
+		end := closingBracket(s)
+		if end < 0 {
+			return "", "", 0
+		}
+		label, rest := s[1:end], s[end+1:]
+		switch {
+		case strings.HasPrefix(rest, "("):
+			close := strings.IndexAny(rest, ") ")
+			if close < 0 || rest[close] != ')' {
+				return "", "", 0
+			}
+			u, n = rest[1:close], end+close+2
+		case strings.HasPrefix(rest, "["):
+			close := closingBracket(rest)
+			if close < 0 {
+				return "", "", 0
+			}
+			ref := rest[1:close]
+			if ref == "" {
+				ref = label
+			}
+			u, n = links[linkLabel(ref)], end+close+2
+		default:
+			u, n = links[linkLabel(label)], end+1
+		}
+		if u == "" || !safeURL(u) {
+			return "", "", 0
+		}
+		return plainText(parseInlines(label, links)), u, n
+	}
+	return "", "", 0
+}
+
+// closingBracket returns the index of the bracket closing the one that s
+// starts with, or -1 if it is not closed.
+func closingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// linkDefinitions removes the link reference definitions from the lines and
+// returns the remaining lines along with the URLs of the definitions by their
+// normalized label. Definitions, such as "[label]: https://example.com", are
+// lines that are not indented and start a block.
+func linkDefinitions(lines []string) ([]string, map[string]string) {
+	links := make(map[string]string)
+	kept := make([]string, 0, len(lines))
+	blockStart := true
+	for _, l := range lines {
+		if blockStart && strings.HasPrefix(l, "[") {
+			end := closingBracket(l)
+			if end > 1 && strings.HasPrefix(l[end+1:], ":") {
+				fields := strings.Fields(l[end+2:])
+				if len(fields) == 1 {
+					// The first definition of a label is used.
+					label := linkLabel(l[1:end])
+					if _, ok := links[label]; !ok {
+						links[label] = fields[0]
+					}
+					continue
+				}
+			}
+		}
+		blockStart = isBlank(l)
+		kept = append(kept, l)
+	}
+	return kept, links
+}
+
+// linkLabel normalizes the label of a link reference, which is matched
+// case-insensitively and with whitespace collapsed.
+func linkLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// parseBareURL returns the URL starting with "http://" or "https://" at the
+// start of s, or an empty string if there is none. Trailing punctuation and
+// unbalanced parentheses are not part of the URL.
+func parseBareURL(s string) string {
+	if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+		return ""
+	}
+	end := strings.IndexAny(s, " <>\"`")
+	if end < 0 {
+		end = len(s)
+	}
+	u := s[:end]
+	for {
+		trimmed := strings.TrimRight(u, ".,:;!?'*_~")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+		if trimmed == u {
+			break
+		}
+		u = trimmed
+	}
+	if strings.HasSuffix(u, "://") || !safeURL(u) {
+		return ""
+	}
+	return u
+}
+
+// safeURL returns true if the URL is relative or uses the http, https or mailto
+// scheme, so that it can be linked to.
+func safeURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// plainText returns the text of the spans without formatting.
+func plainText(inlines []*doc.Inline) string {
+	var b strings.Builder
+	for _, in := range inlines {
+		b.WriteString(in.Text)
+	}
+	return b.String()
+}
+
+// isBlank returns true if the line only contains whitespace.
+func isBlank(l string) bool {
+	return strings.TrimSpace(l) == ""
+}
+
+// indentation returns the number of spaces that the line starts with.
+func indentation(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+// expandTabs replaces the tabs in the indentation of the line by spaces, with
+// tab stops every 4 columns.
+func expandTabs(l string) string {
+	n := len(l) - len(strings.TrimLeft(l, " \t"))
+	if !strings.Contains(l[:n], "\t") {
+		return l
+	}
+	var b strings.Builder
+	for _, c := range l[:n] {
+		if c == '\t' {
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String() + l[n:]
+}
+
+// isAlphanumeric returns true if the byte is an ASCII letter or digit.
+func isAlphanumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// dedent removes the indentation shared by all lines of the comment as well as
+// trailing whitespace and blank lines at the start and the end.
+func dedent(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	indent := -1
+	for i, l := range lines {
+		l = strings.TrimRight(l, " \t")
+		lines[i] = l
+		if l == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
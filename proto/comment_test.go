@@ -0,0 +1,216 @@
+package proto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// blockKinds summarizes the blocks with their kinds, such as
+// "paragraph,list(paragraph;paragraph),code".
+func blockKinds(blocks []*doc.Block) string {
+	kinds := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		kind := b.Kind
+		if b.Kind == doc.BlockList {
+			items := make([]string, 0, len(b.Items))
+			for _, item := range b.Items {
+				items = append(items, item.Number+blockKinds(item.Blocks))
+			}
+			kind += "(" + strings.Join(items, ";") + ")"
+		}
+		kinds = append(kinds, kind)
+	}
+	return strings.Join(kinds, ",")
+}
+
+func TestParseRichText(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		blocks   string
+		markdown string
+		html     string
+	}{
+		{
+			name:     "code spans",
+			comment:  "Set `page_token` to the\n`next_page_token` of the previous response.",
+			blocks:   "paragraph",
+			markdown: "Set `page_token` to the `next_page_token` of the previous response.\n",
+			html:     "<p>Set <code>page_token</code> to the <code>next_page_token</code> of the previous response.</p>\n",
+		},
+		{
+			name:     "code span with backticks",
+			comment:  "Quote with `` ` `` or ``a`b``.",
+			blocks:   "paragraph",
+			markdown: "Quote with `` ` `` or ``a`b``.\n",
+			html:     "<p>Quote with <code>`</code> or <code>a`b</code>.</p>\n",
+		},
+		{
+			name:     "links",
+			comment:  "See [AIP-158](https://google.aip.dev/158), <https://example.com/a> and\nhttps://example.com/b_(c).",
+			blocks:   "paragraph",
+			markdown: "See [AIP-158](https://google.aip.dev/158), <https://example.com/a> and <https://example.com/b_(c)>.\n",
+			html: `<p>See <a href="https://google.aip.dev/158">AIP-158</a>, <a href="https://example.com/a">https://example.com/a</a> and ` +
+				`<a href="https://example.com/b_(c)">https://example.com/b_(c)</a>.</p>` + "\n",
+		},
+		{
+			name:     "reference links",
+			comment:  "See the [guide], [the AIP][aip] and [AIP][].\n\n[Guide]: https://example.com/guide\n[aip]: https://google.aip.dev\n[unused]: https://example.com",
+			blocks:   "paragraph",
+			markdown: "See the [guide](https://example.com/guide), [the AIP](https://google.aip.dev) and [AIP](https://google.aip.dev).\n",
+			html: `<p>See the <a href="https://example.com/guide">guide</a>, <a href="https://google.aip.dev">the AIP</a> and ` +
+				`<a href="https://google.aip.dev">AIP</a>.</p>` + "\n",
+		},
+		{
+			name:     "undefined reference",
+			comment:  "Matrix [i][j].\n[x]: https://example.com",
+			blocks:   "paragraph",
+			markdown: "Matrix \\[i\\]\\[j\\]. \\[x\\]: <https://example.com>\n",
+			html:     "<p>Matrix [i][j]. [x]: <a href=\"https://example.com\">https://example.com</a></p>\n",
+		},
+		{
+			name:     "unsafe link",
+			comment:  "[click](javascript:alert(1))",
+			blocks:   "paragraph",
+			markdown: "\\[click\\](javascript:alert(1))\n",
+			html:     "<p>[click](javascript:alert(1))</p>\n",
+		},
+		{
+			name:     "escaping",
+			comment:  "Use <b>bold</b> & *stars* in [brackets] with a_b, _c_ and &amp;.\n\\`not code\\`",
+			blocks:   "paragraph",
+			markdown: "Use \\<b>bold\\</b> & \\*stars\\* in \\[brackets\\] with a_b, \\_c\\_ and \\&amp;. \\`not code\\`\n",
+			html:     "<p>Use &lt;b&gt;bold&lt;/b&gt; &amp; *stars* in [brackets] with a_b, _c_ and &amp;amp;. `not code`</p>\n",
+		},
+		{
+			name:     "headings",
+			comment:  "# Pagination #\n\nResults are paged.\n## Limits\n#hashtag",
+			blocks:   "heading,paragraph,heading,paragraph",
+			markdown: "##### Pagination\n\nResults are paged.\n\n##### Limits\n\n\\#hashtag\n",
+			html:     "<h5>Pagination</h5>\n<p>Results are paged.</p>\n<h5>Limits</h5>\n<p>#hashtag</p>\n",
+		},
+		{
+			name:     "bullet list",
+			comment:  "Filters:\n- `status`: the status\n  of the order.\n* `email`: the email\nof the buyer.\n\nOthers are ignored.",
+			blocks:   "paragraph,list(paragraph;paragraph),paragraph",
+			markdown: "Filters:\n\n- `status`: the status of the order.\n- `email`: the email of the buyer.\n\nOthers are ignored.\n",
+			html: "<p>Filters:</p>\n<ul>\n<li><code>status</code>: the status of the order.</li>\n" +
+				"<li><code>email</code>: the email of the buyer.</li>\n</ul>\n<p>Others are ignored.</p>\n",
+		},
+		{
+			name:     "numbered list",
+			comment:  "3. First\n4) Second\n\n   Details.",
+			blocks:   "list(3paragraph;4paragraph,paragraph)",
+			markdown: "3. First\n\n4. Second\n\n   Details.\n",
+			html:     "<ol start=\"3\">\n<li><p>First</p>\n</li>\n<li><p>Second</p>\n<p>Details.</p>\n</li>\n</ol>\n",
+		},
+		{
+			name:     "nested list",
+			comment:  "- a\n  - b\n  - c\n- d",
+			blocks:   "list(paragraph,list(paragraph;paragraph);paragraph)",
+			markdown: "- a\n  - b\n  - c\n- d\n",
+			html:     "<ul>\n<li>a\n<ul>\n<li>b</li>\n<li>c</li>\n</ul>\n</li>\n<li>d</li>\n</ul>\n",
+		},
+		{
+			name:     "wrapped number",
+			comment:  "Up to\n100. Or less.",
+			blocks:   "paragraph",
+			markdown: "Up to 100. Or less.\n",
+			html:     "<p>Up to 100. Or less.</p>\n",
+		},
+		{
+			name:     "indented code",
+			comment:  "For example:\n\n    GET /v1/books?page_size=10\n\n      <next>\n\nDone.",
+			blocks:   "paragraph,code,paragraph",
+			markdown: "For example:\n\n```\nGET /v1/books?page_size=10\n\n  <next>\n```\n\nDone.\n",
+			html:     "<p>For example:</p>\n<pre><code>GET /v1/books?page_size=10\n\n  &lt;next&gt;\n</code></pre>\n<p>Done.</p>\n",
+		},
+		{
+			name:     "tab indented code",
+			comment:  "Run:\n\n\tgo test ./...",
+			blocks:   "paragraph,code",
+			markdown: "Run:\n\n```\ngo test ./...\n```\n",
+			html:     "<p>Run:</p>\n<pre><code>go test ./...\n</code></pre>\n",
+		},
+		{
+			name:     "fenced code",
+			comment:  "Body:\n```json\n{\n  \"title\": \"```\"\n}\n```",
+			blocks:   "paragraph,code",
+			markdown: "Body:\n\n````\n{\n  \"title\": \"```\"\n}\n````\n",
+			html:     "<p>Body:</p>\n<pre><code>{\n  &#34;title&#34;: &#34;```&#34;\n}\n</code></pre>\n",
+		},
+		{
+			name:     "leading markers",
+			comment:  "Paragraph\n\n> quote\n\n+1\n\n---",
+			blocks:   "paragraph,paragraph,paragraph,paragraph",
+			markdown: "Paragraph\n\n\\> quote\n\n\\+1\n\n\\---\n",
+			html:     "<p>Paragraph</p>\n<p>&gt; quote</p>\n<p>+1</p>\n<p>---</p>\n",
+		},
+	}
+	for _, test := range tests {
+		rich := parseRichText(test.comment)
+		if got := blockKinds(rich.Blocks); got != test.blocks {
+			t.Errorf("%s: blocks = %s, want %s", test.name, got, test.blocks)
+		}
+		if rich.Markdown != test.markdown {
+			t.Errorf("%s: Markdown =\n%q\nwant\n%q", test.name, rich.Markdown, test.markdown)
+		}
+		if rich.HTML != test.html {
+			t.Errorf("%s: HTML =\n%q\nwant\n%q", test.name, rich.HTML, test.html)
+		}
+	}
+	if rich := parseRichText(" \n\n"); rich != nil {
+		t.Errorf("rich text of blank comment = %+v, want nil", rich)
+	}
+}
+
+func TestParseListItem(t *testing.T) {
+	tests := []struct {
+		line          string
+		ok            bool
+		number        string
+		text          string
+		contentIndent int
+	}{
+		{"- item", true, "", "item", 2},
+		{"  *   item", true, "", "item", 6},
+		{"• item", true, "", "item", 2},
+		{"12. item", true, "12", "item", 4},
+		{"1) item", true, "1", "item", 3},
+		{"-", true, "", "", 2},
+		// Text indented by more than 4 spaces is code.
+		{"-      code", true, "", "     code", 2},
+		{"-item", false, "", "", 0},
+		{"*emphasis*", false, "", "", 0},
+		{"1.5 items", false, "", "", 0},
+		{"1234567890. item", false, "", "", 0},
+		{"item", false, "", "", 0},
+	}
+	for _, test := range tests {
+		item, ok := parseListItem(test.line)
+		if ok != test.ok || item.number != test.number || item.text != test.text || item.contentIndent != test.contentIndent {
+			t.Errorf("parseListItem(%q) = %+v, %v, want number %q, text %q, indent %d, %v",
+				test.line, item, ok, test.number, test.text, test.contentIndent, test.ok)
+		}
+	}
+}
+
+func TestDedent(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+	}{
+		{"", ""},
+		{" Returns a book.\n", "Returns a book."},
+		{"\n  Returns a book.  \n\n    GET /v1/books\n\n", "Returns a book.\n\n  GET /v1/books"},
+		{"\tA\n\t\tB", "A\n\tB"},
+		{" A\r\n B", "A\nB"},
+	}
+	for _, test := range tests {
+		if got := dedent(test.comment); got != test.want {
+			t.Errorf("dedent(%q) = %q, want %q", test.comment, got, test.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package proto
 
 import (
-	"go/doc"
+	godoc "go/doc"
 	"strings"
 	"unicode"
 
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // ConvertCommentSet parses the comment set as a description.
@@ -16,29 +18,90 @@ func ConvertCommentSet(c protogen.CommentSet) Desc {
 // ParseDesc parses the string provided as a description, a comment describing
 // a type, method or value.
 func ParseDesc(s string) Desc {
+	comment := dedent(s)
 	s = clean(s)
-	var deprecated bool
-	if strings.Contains(s, "Deprecated: ") {
-		deprecated = true
-	}
+	_, msg, deprecated := splitNote(s, deprecatedPrefix)
+	_, example, _ := splitNote(s, examplePrefix)
 	return Desc{
-		Text:       s,
-		Deprecated: deprecated,
+		Text:               s,
+		Comment:            comment,
+		Deprecated:         deprecated,
+		DeprecationMessage: msg,
+		Example:            example,
 	}
 }
 
+// Prefixes of notes in descriptions. A note continues until the end of the
+// paragraph or until the next note.
+const (
+	deprecatedPrefix = "Deprecated: "
+	examplePrefix    = "Example: "
+)
+
+// splitNote splits the note with the prefix from the text.
+func splitNote(s, prefix string) (text, note string, ok bool) {
+	i := strings.Index(s, prefix)
+	if i < 0 {
+		return s, "", false
+	}
+	note = s[i+len(prefix):]
+	end := strings.Index(note, "\n\n")
+	for _, other := range []string{deprecatedPrefix, examplePrefix} {
+		for _, sep := range []string{" ", "\n"} {
+			j := strings.Index(note, sep+other)
+			if j >= 0 && (end < 0 || j < end) {
+				end = j
+			}
+		}
+	}
+	rest := ""
+	if end >= 0 {
+		note, rest = note[:end], note[end:]
+	}
+	return strings.TrimSpace(strings.TrimSpace(s[:i]) + rest), strings.TrimSpace(note), true
+}
+
 // Desc is a struct containing information retrieved from the description.
 type Desc struct {
-	Text       string
-	Deprecated bool
+	Text               string
+	Deprecated         bool
+	DeprecationMessage string
+	// Example is the example value in JSON following "Example: ".
+	Example string
+	// Comment is the comment with the indentation shared by all lines
+	// removed. Unlike Text, its line breaks are kept.
+	Comment string
 }
 
-// Long returns the description with the name removed.
+// Long returns the description with the name, the deprecation notice and the
+// example removed.
 func (d Desc) Long(name string) string {
-	text := d.Text
-	if d.Deprecated {
-		text = strings.ReplaceAll(text, "Deprecated: ", "")
+	return trimName(d.withoutNotes(), name)
+}
+
+// withoutNotes returns the description with the deprecation notice and the
+// example removed.
+func (d Desc) withoutNotes() string {
+	text, _, _ := splitNote(d.Text, deprecatedPrefix)
+	text, _, _ = splitNote(text, examplePrefix)
+	return text
+}
+
+// Rich returns the description with the name, the deprecation notice and the
+// example removed, parsed into blocks. It returns nil if the description is
+// empty. If name is empty, the description is kept as is.
+func (d Desc) Rich(name string) *doc.RichText {
+	text, _, _ := splitNote(d.Comment, deprecatedPrefix)
+	text, _, _ = splitNote(text, examplePrefix)
+	if name != "" {
+		text = trimName(text, name)
 	}
+	return parseRichText(text)
+}
+
+// trimName removes the name from the start of the description and capitalizes
+// the first letter of the rest.
+func trimName(text, name string) string {
 	long := strings.TrimPrefix(text, name+" is ")
 	long = strings.TrimPrefix(long, name+" are ")
 	long = strings.TrimPrefix(long, name+" ")
@@ -53,10 +116,28 @@ func (d Desc) Long(name string) string {
 // Short returns the first sentence of the description with the name removed.
 func (d Desc) Short(name string) string {
 	text := d.Long(name)
-	synopsis := doc.Synopsis(text)
+	synopsis := godoc.Synopsis(text)
 	return strings.TrimSuffix(synopsis, ".")
 }
 
+// Deprecation returns the deprecation status of the element with the
+// description, which is deprecated if either the comment or the deprecated
+// option says so.
+func (d Desc) Deprecation(desc protoreflect.Descriptor) doc.Deprecation {
+	return doc.Deprecation{
+		Deprecated:         d.Deprecated || deprecatedOption(desc),
+		DeprecationMessage: d.DeprecationMessage,
+	}
+}
+
+// deprecatedOption returns true if the deprecated option of the element is
+// set. Elements without such an option, such as oneofs, are never deprecated.
+func deprecatedOption(desc protoreflect.Descriptor) bool {
+	opts := desc.Options().ProtoReflect()
+	fd := opts.Descriptor().Fields().ByName("deprecated")
+	return fd != nil && opts.Get(fd).Bool()
+}
+
 // clean rewrites the provided string's whitespaces by removing leading,
 // trailing, and excessive newlines in addition to collapsing multiple word
 // separators into one character.
@@ -0,0 +1,26 @@
+package proto
+
+import "testing"
+
+func TestSplitNote(t *testing.T) {
+	tests := []struct {
+		s, prefix  string
+		text, note string
+		ok         bool
+	}{
+		{"Returns a book.", examplePrefix, "Returns a book.", "", false},
+		{"Returns a book.\nExample: {\"id\": 1}", examplePrefix, "Returns a book.", `{"id": 1}`, true},
+		{"Deprecated: Use GetShelf.", deprecatedPrefix, "", "Use GetShelf.", true},
+		// A note ends at the next note.
+		{"Deprecated: Use GetShelf. Example: \"x\"", deprecatedPrefix, `Example: "x"`, "Use GetShelf.", true},
+		{"Deprecated: Use GetShelf.\nExample: \"x\"", examplePrefix, "Deprecated: Use GetShelf.", `"x"`, true},
+		// A note ends at the end of its paragraph.
+		{"Old.\n\nDeprecated: Gone\nfor good.\n\nMore text.", deprecatedPrefix, "Old.\n\nMore text.", "Gone\nfor good.", true},
+	}
+	for _, test := range tests {
+		text, note, ok := splitNote(test.s, test.prefix)
+		if text != test.text || note != test.note || ok != test.ok {
+			t.Errorf("splitNote(%q, %q) = %q, %q, %v, want %q, %q, %v", test.s, test.prefix, text, note, ok, test.text, test.note, test.ok)
+		}
+	}
+}
@@ -3,20 +3,39 @@ package proto
 import (
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // ConvertEnum converts the provided protogen enum to a doc enum.
 func ConvertEnum(e *protogen.Enum) *doc.Enum {
-	name := string(e.Desc.Name())
+	name := scopedName(e.Desc)
 	desc := ConvertCommentSet(e.Comments)
 	val := make([]*doc.EnumVal, 0, len(e.Values))
+	names := make(map[protoreflect.EnumNumber][]string)
 	for _, v := range e.Values {
 		val = append(val, ConvertEnumVal(v))
+		names[v.Desc.Number()] = append(names[v.Desc.Number()], string(v.Desc.Name()))
+	}
+	// The first value is the default in both proto2 and proto3.
+	var def protoreflect.EnumNumber
+	if len(e.Values) > 0 {
+		def = e.Values[0].Desc.Number()
+	}
+	for i, v := range e.Values {
+		val[i].Default = v.Desc.Number() == def
+		for _, name := range names[v.Desc.Number()] {
+			if name != val[i].Value {
+				val[i].Aliases = append(val[i].Aliases, name)
+			}
+		}
 	}
 	return &doc.Enum{
-		Name:        name,
-		Description: desc.Long(name),
-		Values:      val,
+		Name:            name,
+		Description:     desc.Long(string(e.Desc.Name())),
+		RichDescription: desc.Rich(string(e.Desc.Name())),
+		Deprecation:     desc.Deprecation(e.Desc),
+		Parent:          parentName(e.Desc),
+		Values:          val,
 	}
 }
 
@@ -26,6 +45,9 @@ func ConvertEnumVal(v *protogen.EnumValue) *doc.EnumVal {
 	desc := ConvertCommentSet(v.Comments)
 	return &doc.EnumVal{
 		Value:       name,
+		DisplayName: name,
+		Number:      int32(v.Desc.Number()),
 		Description: desc.Short(name),
+		Deprecation: desc.Deprecation(v.Desc),
 	}
 }
@@ -0,0 +1,258 @@
+package proto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// setExample sets the example of the type of a field with the proto name if it
+// is a basic type without one.
+func setExample(t doc.Type, name string) {
+	if b, ok := t.(*doc.Basic); ok && b.Example == "" {
+		b.Example = exampleValue(b, name)
+	}
+}
+
+// exampleValue returns an example of the basic type in JSON, guessed from the
+// proto name of the field and the format of the type.
+func exampleValue(t *doc.Basic, name string) string {
+	switch strings.TrimPrefix(t.Name, "Nullable ") {
+	case "Boolean":
+		return "true"
+	case "Integer", "Unsigned Integer":
+		return exampleInteger(name)
+	case "Integer(64)", "Unsigned Integer(64)":
+		return jsonString(exampleInteger(name))
+	case "Float(32)", "Float(64)":
+		return exampleFloat(name)
+	case "String":
+		return jsonString(exampleString(name, ""))
+	case "Bytes":
+		return jsonString("aGVsbG8gd29ybGQ=")
+	case "Any":
+		return `{"@type":"type.googleapis.com/google.protobuf.Duration","value":"1.5s"}`
+	case "Duration":
+		return jsonString("1.5s")
+	case "Empty":
+		return "{}"
+	case "Field Mask":
+		return jsonString("displayName,description")
+	case "JSON":
+		return jsonString("value")
+	case "JSON List":
+		return `["value"]`
+	case "JSON Struct":
+		return `{"key":"value"}`
+	case "Null":
+		return "null"
+	case "Timestamp":
+		return jsonString("2024-01-15T09:30:00Z")
+	}
+	// Custom scalars only have their encoding.
+	if t.Encoding == nil {
+		return ""
+	}
+	switch t.Encoding.Type {
+	case "string":
+		return jsonString(exampleString(name, t.Encoding.Format))
+	case "integer":
+		return exampleInteger(name)
+	case "number":
+		return exampleFloat(name)
+	case "boolean":
+		return "true"
+	case "object":
+		return "{}"
+	case "array":
+		return "[]"
+	case "null":
+		return "null"
+	}
+	return ""
+}
+
+// exampleInteger returns an example integer for the field name.
+func exampleInteger(name string) string {
+	switch {
+	case hasWord(name, "size", "count", "limit", "quantity", "total"):
+		return "10"
+	case hasWord(name, "year"):
+		return "2024"
+	case hasWord(name, "month"):
+		return "1"
+	case hasWord(name, "day"):
+		return "15"
+	case hasWord(name, "id"):
+		return "1234567890"
+	}
+	return "42"
+}
+
+// exampleFloat returns an example number for the field name.
+func exampleFloat(name string) string {
+	switch {
+	case hasWord(name, "latitude", "lat"):
+		return "37.422"
+	case hasWord(name, "longitude", "lng", "lon"):
+		return "-122.084"
+	case hasWord(name, "price", "amount", "cost"):
+		return "9.99"
+	}
+	return "1.5"
+}
+
+// exampleString returns an example string for the field name and the format
+// of the string.
+func exampleString(name, format string) string {
+	switch {
+	case format == "email" || hasWord(name, "email"):
+		return "jane.doe@example.com"
+	case format == "uuid" || hasWord(name, "uuid", "guid"):
+		return "123e4567-e89b-12d3-a456-426614174000"
+	case format == "uri" || hasWord(name, "url", "uri", "link", "website"):
+		return "https://example.com"
+	case format == "date-time" || hasWord(name, "time", "timestamp"):
+		return "2024-01-15T09:30:00Z"
+	case format == "date" || hasWord(name, "date", "day", "birthday"):
+		return "2024-01-15"
+	case hasWord(name, "phone"):
+		return "+1-202-555-0100"
+	case hasWord(name, "ip"):
+		return "192.0.2.1"
+	case hasWord(name, "currency"):
+		return "USD"
+	case hasWord(name, "country"):
+		return "US"
+	case hasWord(name, "language", "locale"):
+		return "en-US"
+	case hasWord(name, "token", "cursor"):
+		return "eyJvZmZzZXQiOjEwfQ"
+	case hasWord(name, "id", "sku", "key"):
+		return "abc123"
+	case hasWord(name, "title", "name"):
+		return "Example"
+	case hasWord(name, "description", "note", "comment", "query"):
+		return "Lorem ipsum dolor sit amet."
+	}
+	return "string"
+}
+
+// hasWord returns true if the snake case name contains any of the words.
+func hasWord(name string, words ...string) bool {
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		for _, w := range words {
+			if part == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeExample returns the example as the proto3 JSON of the message, so
+// that fields written with their proto names use their JSON names as in
+// generated examples. Fields set to their default value are left out. It
+// returns an error if the example is not the proto3 JSON of the message.
+// Missing required fields are allowed if partial is true.
+func normalizeExample(md protoreflect.MessageDescriptor, example string, partial bool) (json.RawMessage, error) {
+	msg := dynamicpb.NewMessage(md)
+	if err := (protojson.UnmarshalOptions{AllowPartial: partial}).Unmarshal([]byte(example), msg); err != nil {
+		return nil, err
+	}
+	b, err := protojson.MarshalOptions{AllowPartial: partial}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	// The output of protojson is deliberately unstable in whitespace.
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonString returns the string as a JSON string.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// LoadFixtures loads the example fixtures of the methods in the files from the
+// directory. Each fixture is named "<method full name>.json" and its examples
+// must be the proto3 JSON of the request and response messages, which are
+// normalized to use JSON names. The fixtures are keyed by the full gRPC method
+// name of the endpoint. Problems found are reported to d.
+func LoadFixtures(dir string, files []*protogen.File, d *diag.List) map[string]*doc.Fixture {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		d.Errorf(diag.Pos{File: dir}, "cannot read examples directory: %v", err)
+		return nil
+	}
+	methods := make(map[string]*protogen.Method)
+	for _, f := range files {
+		for _, s := range f.Services {
+			for _, m := range s.Methods {
+				methods[string(m.Desc.FullName())] = m
+			}
+		}
+	}
+	fixtures := make(map[string]*doc.Fixture)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		path := filepath.Join(dir, entry.Name())
+		m, ok := methods[name]
+		if !ok {
+			d.Errorf(diag.Pos{File: path}, "examples of unknown method %q", name)
+			continue
+		}
+		fixture, err := loadFixture(path, m)
+		if err != nil {
+			d.Errorf(diag.Pos{File: path}, "invalid examples for method %q: %v", name, err)
+			continue
+		}
+		fixtures[fmt.Sprintf("/%s/%s", m.Parent.Desc.FullName(), m.Desc.Name())] = fixture
+	}
+	return fixtures
+}
+
+// loadFixture loads the fixture at the path and validates it against the
+// request and response of the method.
+func loadFixture(path string, m *protogen.Method) (*doc.Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	fixture := &doc.Fixture{}
+	if err := dec.Decode(fixture); err != nil {
+		return nil, err
+	}
+	if fixture.Request != nil {
+		fixture.Request, err = normalizeExample(m.Input.Desc, string(fixture.Request), false)
+		if err != nil {
+			return nil, fmt.Errorf("request: %w", err)
+		}
+	}
+	if fixture.Response != nil {
+		fixture.Response, err = normalizeExample(m.Output.Desc, string(fixture.Response), false)
+		if err != nil {
+			return nil, fmt.Errorf("response: %w", err)
+		}
+	}
+	return fixture, nil
+}
@@ -0,0 +1,84 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		request  string
+		response string
+		err      string
+	}{
+		{
+			name:     "proto names",
+			file:     "path.Books.UpdateBook.json",
+			content:  `{"request": {"the_book": {"book_id": "b1"}}, "response": {"book_id": "b1"}}`,
+			request:  `{"theBook":{"id":"b1"}}`,
+			response: `{"id":"b1"}`,
+		},
+		{
+			name:    "JSON names",
+			file:    "path.Books.UpdateBook.json",
+			content: `{"request": {"theBook": {"id": "b1"}}}`,
+			request: `{"theBook":{"id":"b1"}}`,
+		},
+		{
+			name:    "unknown method",
+			file:    "path.Books.DeleteBook.json",
+			content: `{}`,
+			err:     `examples of unknown method "path.Books.DeleteBook"`,
+		},
+		{
+			name:    "unknown key",
+			file:    "path.Books.UpdateBook.json",
+			content: `{"requests": {}}`,
+			err:     `unknown field "requests"`,
+		},
+		{
+			name:    "invalid request",
+			file:    "path.Books.UpdateBook.json",
+			content: `{"request": {"book": {}}}`,
+			err:     "invalid examples for method \"path.Books.UpdateBook\": request:",
+		},
+	}
+	p := newPlugin(t, pathFile)
+	for _, test := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, test.file), []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Files other than JSON are ignored.
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var d diag.List
+		fixtures := LoadFixtures(dir, p.Files, &d)
+		if test.err != "" {
+			if err := d.Err(); err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: error = %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		fixture := fixtures["/path.Books/UpdateBook"]
+		if fixture == nil {
+			t.Errorf("%s: fixtures = %v, want UpdateBook", test.name, fixtures)
+			continue
+		}
+		if string(fixture.Request) != test.request || string(fixture.Response) != test.response {
+			t.Errorf("%s: fixture = %s, %s, want %s, %s", test.name, fixture.Request, fixture.Response, test.request, test.response)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package proto
 
 import (
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -9,8 +10,10 @@ import (
 // pkgPath is the path to the comment of a package hard-coded in descriptorpb.
 var pkgPath = protoreflect.SourcePath{2}
 
-// ConvertFile converts the provided protogen file to a package.
-func ConvertFile(f *protogen.File) *doc.Package {
+// ConvertFile converts the provided protogen file to a package, documenting the
+// messages in scalars as basic types. scalars and msgs are shared by the
+// conversion of every file. Problems found are reported to d.
+func ConvertFile(f *protogen.File, scalars Scalars, msgs Messages, d *diag.List) *doc.Package {
 	name := string(f.GoPackageName)
 	path := string(f.Proto.GetPackage())
 	desc := ParseDesc(f.Desc.SourceLocations().ByPath(pkgPath).LeadingComments)
@@ -24,7 +27,7 @@ func ConvertFile(f *protogen.File) *doc.Package {
 		if msg.Desc.IsMapEntry() {
 			continue
 		}
-		msg, extra := ConvertMessage(msg)
+		msg, extra := ConvertMessage(msg, scalars, msgs, d)
 		typ[msg.Name] = msg
 		for _, t := range extra {
 			t := t.(doc.NamedType)
@@ -33,13 +36,28 @@ func ConvertFile(f *protogen.File) *doc.Package {
 	}
 	services := make([]*doc.Service, 0, len(f.Services))
 	for _, s := range f.Services {
-		services = append(services, ConvertService(s))
+		services = append(services, ConvertService(s, scalars, msgs, d))
 	}
 	return &doc.Package{
-		Name:        name,
-		ID:          path,
-		Description: desc.Text,
-		Services:    services,
-		Types:       typ,
+		Name:            name,
+		ID:              path,
+		Description:     desc.withoutNotes(),
+		RichDescription: desc.Rich(""),
+		Deprecation:     desc.Deprecation(f.Desc),
+		Files:           []string{f.Desc.Path()},
+		Services:        services,
+		Types:           typ,
 	}
 }
+
+// pos returns the position that the descriptor is declared at.
+func pos(desc protoreflect.Descriptor) diag.Pos {
+	f := desc.ParentFile()
+	p := diag.Pos{File: f.Path()}
+	loc := f.SourceLocations().ByDescriptor(desc)
+	if len(loc.Path) > 0 {
+		p.Line = loc.StartLine + 1
+		p.Column = loc.StartColumn + 1
+	}
+	return p
+}
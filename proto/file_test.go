@@ -0,0 +1,32 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+)
+
+// deprecatedFile is a deprecated package.
+const deprecatedFile = `
+name: "old.proto"
+package: "old"
+syntax: "proto3"
+source_code_info {
+  location {
+    path: [2] span: [4, 0, 12]
+    leading_comments: " Package old is the old API.\n\n Deprecated: Use the new package.\n"
+  }
+}
+`
+
+func TestConvertFileDescription(t *testing.T) {
+	p := newPlugin(t, deprecatedFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "old.proto"), nil, make(Messages), &d)
+	if want := "Package old is the old API."; pkg.Description != want {
+		t.Errorf("description = %q, want %q", pkg.Description, want)
+	}
+	if !pkg.Deprecated || pkg.DeprecationMessage != "Use the new package." {
+		t.Errorf("deprecation = %+v, want deprecated with the message", pkg.Deprecation)
+	}
+}
@@ -1,48 +1,215 @@
 package proto
 
 import (
+	"encoding/json"
 	"strings"
 
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// Messages maps the full names of messages to their conversion. A message is
+// only converted once even if it is the request or response of methods in
+// other files, so that every problem with it is only reported once.
+type Messages map[protoreflect.FullName]*doc.Message
+
 // ConvertMessage converts the provided protogen message to a doc message. It
-// also returns every nested type.
-func ConvertMessage(m *protogen.Message) (*doc.Message, []doc.Type) {
-	pkgName := string(m.Desc.ParentFile().Package())
-	fullName := string(m.Desc.FullName())
-	name := strings.TrimPrefix(fullName, pkgName+".")
-	desc := ConvertCommentSet(m.Comments)
+// also returns every nested type. Converted messages are recorded in msgs and
+// reused if the message is converted again. Fields of messages in scalars are
+// basic types. Problems found are reported to d.
+func ConvertMessage(m *protogen.Message, scalars Scalars, msgs Messages, d *diag.List) (*doc.Message, []doc.Type) {
 	nestedTypes := make([]doc.Type, 0, len(m.Enums)+len(m.Messages))
 	for _, e := range m.Enums {
 		nestedTypes = append(nestedTypes, ConvertEnum(e))
 	}
 	for _, nestedMsg := range m.Messages {
-		converted, recursedTypes := ConvertMessage(nestedMsg)
+		converted, recursedTypes := ConvertMessage(nestedMsg, scalars, msgs, d)
 		nestedTypes = append(nestedTypes, converted)
 		nestedTypes = append(nestedTypes, recursedTypes...)
 	}
+	if msg, ok := msgs[m.Desc.FullName()]; ok {
+		return msg, nestedTypes
+	}
+	name := scopedName(m.Desc)
+	desc := ConvertCommentSet(m.Comments)
 	fields := make([]*doc.Field, 0, len(m.Fields))
 	for _, f := range m.Fields {
-		fields = append(fields, ConvertField(f))
+		fields = append(fields, ConvertField(f, scalars, d))
+	}
+	oneofs := make([]*doc.Oneof, 0, len(m.Oneofs))
+	for _, o := range m.Oneofs {
+		// Synthetic oneofs only track the presence of proto3 optional
+		// fields.
+		if o.Desc.IsSynthetic() {
+			continue
+		}
+		oneofs = append(oneofs, ConvertOneof(o))
+	}
+	var example json.RawMessage
+	if desc.Example != "" {
+		var err error
+		example, err = normalizeExample(m.Desc, desc.Example, false)
+		if err != nil {
+			d.Errorf(pos(m.Desc), "invalid example for message %q: %v", m.Desc.FullName(), err)
+		}
 	}
 	msg := &doc.Message{
-		Name:        name,
-		Description: desc.Long(m.GoIdent.GoName),
-		Fields:      fields,
+		Name:            name,
+		Description:     desc.Long(string(m.Desc.Name())),
+		RichDescription: desc.Rich(string(m.Desc.Name())),
+		Deprecation:     desc.Deprecation(m.Desc),
+		Parent:          parentName(m.Desc),
+		Fields:          fields,
+		Oneofs:          oneofs,
+		Example:         example,
 	}
+	// Wire-level information.
+	msg.ReservedRanges = fieldRanges(m.Desc.ReservedRanges())
+	reservedNames := m.Desc.ReservedNames()
+	for i := 0; i < reservedNames.Len(); i++ {
+		msg.ReservedNames = append(msg.ReservedNames, string(reservedNames.Get(i)))
+	}
+	msg.ExtensionRanges = fieldRanges(m.Desc.ExtensionRanges())
+	msgs[m.Desc.FullName()] = msg
 	return msg, nestedTypes
 }
 
-// ConvertField converts the provided protogen field to a doc field.
-func ConvertField(f *protogen.Field) *doc.Field {
+// fieldRanges converts the half-open ranges of field numbers to inclusive
+// ranges.
+func fieldRanges(r protoreflect.FieldRanges) []*doc.FieldRange {
+	ranges := make([]*doc.FieldRange, 0, r.Len())
+	for i := 0; i < r.Len(); i++ {
+		rng := r.Get(i)
+		ranges = append(ranges, &doc.FieldRange{
+			Start: int32(rng[0]),
+			End:   int32(rng[1]) - 1,
+		})
+	}
+	return ranges
+}
+
+// ConvertOneof converts the provided protogen oneof to a doc oneof.
+func ConvertOneof(o *protogen.Oneof) *doc.Oneof {
+	desc := ConvertCommentSet(o.Comments)
+	fields := make([]string, 0, len(o.Fields))
+	for _, f := range o.Fields {
+		fields = append(fields, f.Desc.JSONName())
+	}
+	return &doc.Oneof{
+		Name:        string(o.Desc.Name()),
+		Description: desc.Short(o.GoName),
+		Deprecation: desc.Deprecation(o.Desc),
+		Fields:      fields,
+	}
+}
+
+// scopedName returns the name of the descriptor relative to its package, such
+// as "Order.Status" for an enum nested in a message.
+func scopedName(desc protoreflect.Descriptor) string {
+	pkgName := string(desc.ParentFile().Package())
+	return strings.TrimPrefix(string(desc.FullName()), pkgName+".")
+}
+
+// parentName returns the scoped name of the message the descriptor is nested
+// in, or an empty string if it is declared at the top level of the file.
+func parentName(desc protoreflect.Descriptor) string {
+	parent, ok := desc.Parent().(protoreflect.MessageDescriptor)
+	if !ok {
+		return ""
+	}
+	return scopedName(parent)
+}
+
+// ConvertField converts the provided protogen field to a doc field, whose type
+// is a basic type if it is a message in scalars. Problems found are reported
+// to d.
+func ConvertField(f *protogen.Field, scalars Scalars, d *diag.List) *doc.Field {
 	jsonName := f.Desc.JSONName()
 	desc := ConvertCommentSet(f.Comments)
+	var oneof string
+	if f.Oneof != nil && !f.Oneof.Desc.IsSynthetic() {
+		oneof = string(f.Oneof.Desc.Name())
+	}
+	typ := fieldType(f, scalars, pos(f.Desc), d)
+	var example json.RawMessage
+	if desc.Example != "" {
+		// Fields are validated as the only field set in their message.
+		wrapped := "{" + jsonString(jsonName) + ":" + desc.Example + "}"
+		normalized, err := normalizeExample(f.Parent.Desc, wrapped, true)
+		if err != nil {
+			d.Errorf(pos(f.Desc), "invalid example for field %q: %v", f.Desc.FullName(), err)
+		} else {
+			// Default values are left out of the normalized message.
+			var members map[string]json.RawMessage
+			example = json.RawMessage(desc.Example)
+			if json.Unmarshal(normalized, &members) == nil && members[jsonName] != nil {
+				example = members[jsonName]
+			}
+			if b, ok := typ.(*doc.Basic); ok {
+				b.Example = string(example)
+			}
+		}
+	}
 	return &doc.Field{
 		Name:        jsonName,
 		GunkName:    f.GoName,
 		Description: desc.Short(f.GoName),
-		Type:        fieldType(f),
+		Deprecation: desc.Deprecation(f.Desc),
+		Type:        typ,
+		Number:      int32(f.Desc.Number()),
+		Packed:      f.Desc.IsPacked(),
+		Oneof:       oneof,
+		Presence:    presence(f.Desc),
+		Behaviors:   fieldBehaviors(f.Desc),
+		Example:     example,
+	}
+}
+
+// fieldBehaviors returns the google.api.field_behavior annotations of the
+// field.
+func fieldBehaviors(f protoreflect.FieldDescriptor) []string {
+	opt := f.Options()
+	if !proto.HasExtension(opt, annotations.E_FieldBehavior) {
+		return nil
+	}
+	behaviors := proto.GetExtension(opt, annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	names := make([]string, 0, len(behaviors))
+	for _, b := range behaviors {
+		names = append(names, b.String())
+	}
+	return names
+}
+
+// hasBehavior returns true if the field is annotated with the behavior.
+func hasBehavior(f protoreflect.FieldDescriptor, behavior string) bool {
+	for _, b := range fieldBehaviors(f) {
+		if b == behavior {
+			return true
+		}
+	}
+	return false
+}
+
+// required returns true if the field is a proto2 required field or has the
+// REQUIRED behavior.
+func required(f protoreflect.FieldDescriptor) bool {
+	return f.Cardinality() == protoreflect.Required || hasBehavior(f, doc.BehaviorRequired)
+}
+
+// presence returns the presence of the field.
+func presence(f protoreflect.FieldDescriptor) string {
+	switch {
+	case f.IsList(), f.IsMap():
+		return doc.PresenceRepeated
+	case f.Cardinality() == protoreflect.Required:
+		return doc.PresenceRequired
+	case f.HasPresence():
+		return doc.PresenceExplicit
+	default:
+		return doc.PresenceImplicit
 	}
 }
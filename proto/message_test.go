@@ -0,0 +1,200 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// wireFiles declare messages with reserved numbers and names, extension
+// ranges and repeated scalars in proto2 and proto3.
+var wireFiles = []string{`
+name: "wire2.proto"
+package: "wire2"
+syntax: "proto2"
+message_type {
+  name: "Legacy"
+  field { name: "unpacked" number: 1 type: TYPE_INT32 label: LABEL_REPEATED json_name: "unpacked" }
+  field { name: "packed" number: 2 type: TYPE_INT32 label: LABEL_REPEATED json_name: "packed" options { packed: true } }
+  reserved_range { start: 5 end: 6 }
+  reserved_range { start: 10 end: 21 }
+  reserved_name: "old"
+  reserved_name: "older"
+  extension_range { start: 100 end: 200 }
+  extension_range { start: 1000 end: 536870912 }
+}
+`, `
+name: "wire3.proto"
+package: "wire3"
+syntax: "proto3"
+message_type {
+  name: "Modern"
+  field { name: "packed" number: 1 type: TYPE_INT32 label: LABEL_REPEATED json_name: "packed" }
+  field { name: "unpacked" number: 2 type: TYPE_INT32 label: LABEL_REPEATED json_name: "unpacked" options { packed: false } }
+  field { name: "names" number: 3 type: TYPE_STRING label: LABEL_REPEATED json_name: "names" }
+  field { name: "single" number: 4 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "single" }
+}
+`}
+
+// rangeText returns the ranges in the form of "start-end".
+func rangeText(ranges []*doc.FieldRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		parts = append(parts, fmt.Sprintf("%d-%d", r.Start, r.End))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestConvertMessageWire(t *testing.T) {
+	p := newPlugin(t, wireFiles...)
+	var d diag.List
+	msgs := make(Messages)
+	legacy := ConvertFile(generatedFile(t, p, "wire2.proto"), nil, msgs, &d).Types["Legacy"].(*doc.Message)
+	modern := ConvertFile(generatedFile(t, p, "wire3.proto"), nil, msgs, &d).Types["Modern"].(*doc.Message)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := rangeText(legacy.ReservedRanges), "5-5,10-20"; got != want {
+		t.Errorf("reserved ranges = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(legacy.ReservedNames, ","), "old,older"; got != want {
+		t.Errorf("reserved names = %s, want %s", got, want)
+	}
+	if got, want := rangeText(legacy.ExtensionRanges), fmt.Sprintf("100-199,1000-%d", doc.MaxFieldNumber); got != want {
+		t.Errorf("extension ranges = %s, want %s", got, want)
+	}
+	if len(modern.ReservedRanges) != 0 || len(modern.ReservedNames) != 0 || len(modern.ExtensionRanges) != 0 {
+		t.Errorf("message without reserved numbers has wire information: %+v", modern)
+	}
+
+	tests := []struct {
+		msg    *doc.Message
+		field  int
+		packed bool
+	}{
+		// Repeated scalars are only packed by default in proto3.
+		{legacy, 0, false},
+		{legacy, 1, true},
+		{modern, 0, true},
+		{modern, 1, false},
+		// Strings and singular fields cannot be packed.
+		{modern, 2, false},
+		{modern, 3, false},
+	}
+	for _, test := range tests {
+		f := test.msg.Fields[test.field]
+		if f.Packed != test.packed {
+			t.Errorf("%s.%s packed = %t, want %t", test.msg.Name, f.Name, f.Packed, test.packed)
+		}
+		if f.Number != int32(test.field+1) {
+			t.Errorf("%s.%s number = %d, want %d", test.msg.Name, f.Name, f.Number, test.field+1)
+		}
+	}
+}
+
+// nestedFile declares types nested in a message.
+const nestedFile = `
+name: "nested.proto"
+package: "nested"
+syntax: "proto3"
+message_type {
+  name: "Order"
+  field { name: "items" number: 1 type: TYPE_MESSAGE label: LABEL_REPEATED type_name: ".nested.Order.Item" json_name: "items" }
+  field { name: "status" number: 2 type: TYPE_ENUM label: LABEL_OPTIONAL type_name: ".nested.Order.Status" json_name: "status" }
+  nested_type {
+    name: "Item"
+    field { name: "kind" number: 1 type: TYPE_ENUM label: LABEL_OPTIONAL type_name: ".nested.Order.Item.Kind" json_name: "kind" }
+    enum_type { name: "Kind" value { name: "KIND_UNSPECIFIED" number: 0 } }
+  }
+  enum_type { name: "Status" value { name: "STATUS_UNSPECIFIED" number: 0 } }
+}
+`
+
+func TestConvertNestedTypes(t *testing.T) {
+	p := newPlugin(t, nestedFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "nested.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		parent string
+	}{
+		{"Order", ""},
+		{"Order.Item", "Order"},
+		{"Order.Item.Kind", "Order.Item"},
+		{"Order.Status", "Order"},
+	}
+	if len(pkg.Types) != len(tests) {
+		t.Errorf("%d types converted, want %d", len(pkg.Types), len(tests))
+	}
+	for _, test := range tests {
+		var name, parent string
+		switch typ := pkg.Types[test.name].(type) {
+		case *doc.Message:
+			name, parent = typ.Name, typ.Parent
+		case *doc.Enum:
+			name, parent = typ.Name, typ.Parent
+		default:
+			t.Errorf("type %s = %#v, want a message or enum", test.name, typ)
+			continue
+		}
+		if name != test.name || parent != test.parent {
+			t.Errorf("type %s is named %q with parent %q, want parent %q", test.name, name, parent, test.parent)
+		}
+	}
+	fields := pkg.Types["Order"].(*doc.Message).Fields
+	if ref := fields[0].Type.(*doc.Array).Value.(*doc.Ref); ref.Name != "nested.Order.Item" {
+		t.Errorf("items reference %s, want nested.Order.Item", ref.Name)
+	}
+	if ref := fields[1].Type.(*doc.Ref); ref.Name != "nested.Order.Status" {
+		t.Errorf("status references %s, want nested.Order.Status", ref.Name)
+	}
+}
+
+// oneofFile declares a message with a oneof.
+const oneofFile = `
+name: "oneof.proto"
+package: "oneof"
+syntax: "proto3"
+message_type {
+  name: "Book"
+  field { name: "title" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "title" }
+  field { name: "isbn" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "isbn" oneof_index: 0 }
+  field { name: "web_url" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "webUrl" oneof_index: 0 }
+  oneof_decl { name: "source" }
+}
+source_code_info {
+  location { path: [4, 0, 8, 0] span: [7, 2, 10, 3] leading_comments: " The source of the book.\n" }
+}
+`
+
+func TestConvertOneofs(t *testing.T) {
+	p := newPlugin(t, oneofFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "oneof.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	msg := pkg.Types["Book"].(*doc.Message)
+	if len(msg.Oneofs) != 1 {
+		t.Fatalf("oneofs = %v, want source", msg.Oneofs)
+	}
+	o := msg.Oneofs[0]
+	if o.Name != "source" || o.Description != "The source of the book" {
+		t.Errorf("oneof is named %q with description %q, want source with its comment", o.Name, o.Description)
+	}
+	if got, want := strings.Join(o.Fields, ","), "isbn,webUrl"; got != want {
+		t.Errorf("fields of source = %s, want %s", got, want)
+	}
+	for i, want := range []string{"", "source", "source"} {
+		if f := msg.Fields[i]; f.Oneof != want {
+			t.Errorf("oneof of %s = %q, want %q", f.Name, f.Oneof, want)
+		}
+	}
+}
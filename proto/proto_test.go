@@ -0,0 +1,76 @@
+package proto
+
+import (
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// testDeps are the files that test files may import.
+var testDeps = []protoreflect.FileDescriptor{
+	descriptorpb.File_google_protobuf_descriptor_proto,
+	annotations.File_google_api_http_proto,
+	annotations.File_google_api_annotations_proto,
+	annotations.File_google_api_field_behavior_proto,
+	anypb.File_google_protobuf_any_proto,
+	durationpb.File_google_protobuf_duration_proto,
+	emptypb.File_google_protobuf_empty_proto,
+	fieldmaskpb.File_google_protobuf_field_mask_proto,
+	structpb.File_google_protobuf_struct_proto,
+	timestamppb.File_google_protobuf_timestamp_proto,
+	wrapperspb.File_google_protobuf_wrappers_proto,
+}
+
+// newPlugin returns a plugin generating the files, which are
+// FileDescriptorProtos in the text format. The files may import testDeps and
+// get a Go package named after their proto package by default.
+func newPlugin(t *testing.T, files ...string) *protogen.Plugin {
+	t.Helper()
+	req := &pluginpb.CodeGeneratorRequest{}
+	for _, dep := range testDeps {
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(dep))
+	}
+	for _, text := range files {
+		f := &descriptorpb.FileDescriptorProto{}
+		if err := prototext.Unmarshal([]byte(text), f); err != nil {
+			t.Fatal(err)
+		}
+		if f.Options == nil {
+			f.Options = &descriptorpb.FileOptions{}
+		}
+		if f.Options.GoPackage == nil {
+			f.Options.GoPackage = proto.String("example.com/" + f.GetPackage())
+		}
+		req.ProtoFile = append(req.ProtoFile, f)
+		req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+	}
+	p, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// generatedFile returns the file of the plugin with the path.
+func generatedFile(t *testing.T, p *protogen.Plugin, path string) *protogen.File {
+	t.Helper()
+	f, ok := p.FilesByPath[path]
+	if !ok {
+		t.Fatalf("file %q not found", path)
+	}
+	return f
+}
@@ -0,0 +1,110 @@
+package proto
+
+import (
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// queryParams returns the parameters of the route of the method that are
+// passed in the query string. Following the grpc-gateway transcoding rules,
+// every field of the request that is neither bound by the path nor part of
+// the body is a query parameter, with nested messages flattened into dotted
+// names. Maps, repeated messages and well-known types without a scalar JSON
+// value, such as google.protobuf.Empty, cannot be passed in the query string
+// and are left out, as are OUTPUT_ONLY fields which the server ignores.
+// Messages in scalars are treated like well-known types. Problems found are
+// reported to d.
+func queryParams(m *protogen.Method, route *doc.Route, scalars Scalars, d *diag.List) []*doc.Param {
+	if route.BodyField == "*" {
+		return nil
+	}
+	q := &queryWalker{
+		bound:    make(map[string]bool, len(route.PathParams)),
+		visiting: make(map[protoreflect.FullName]bool),
+		scalars:  scalars,
+		diags:    d,
+	}
+	for _, p := range route.PathParams {
+		q.bound[p.Name] = true
+	}
+	for _, f := range m.Input.Fields {
+		if f.Desc.JSONName() == route.BodyField {
+			continue
+		}
+		q.field(f, "", "")
+	}
+	return q.params
+}
+
+// queryWalker is the state of queryParams.
+type queryWalker struct {
+	// bound is the set of proto field paths bound by the path.
+	bound map[string]bool
+	// visiting is the set of messages being walked, used to stop at
+	// recursive messages.
+	visiting map[protoreflect.FullName]bool
+	scalars  Scalars
+	diags    *diag.List
+	params   []*doc.Param
+}
+
+// field adds the parameters for the field, whose parent has the provided
+// proto path and JSON path.
+func (q *queryWalker) field(f *protogen.Field, protoPrefix, jsonPrefix string) {
+	protoPath := protoPrefix + string(f.Desc.Name())
+	jsonPath := jsonPrefix + f.Desc.JSONName()
+	if q.bound[protoPath] || f.Desc.IsMap() || hasBehavior(f.Desc, doc.BehaviorOutputOnly) {
+		return
+	}
+	if f.Message != nil {
+		if _, ok := q.scalars.basicMessage(string(f.Message.Desc.FullName())); !ok {
+			q.message(f, protoPath, jsonPath)
+			return
+		}
+	}
+	typ := fieldType(f, q.scalars, pos(f.Desc), q.diags)
+	if arr, ok := typ.(*doc.Array); ok {
+		typ = arr.Value
+	}
+	if basic, ok := typ.(*doc.Basic); ok && !scalarJSON(basic) {
+		return
+	}
+	desc := ConvertCommentSet(f.Comments)
+	q.params = append(q.params, &doc.Param{
+		Name:        jsonPath,
+		JSONPath:    jsonPath,
+		Description: desc.Short(f.GoName),
+		Deprecation: desc.Deprecation(f.Desc),
+		Type:        typ,
+		Repeated:    f.Desc.IsList(),
+		Required:    required(f.Desc),
+	})
+}
+
+// message adds the parameters for the fields of the message in the field.
+func (q *queryWalker) message(f *protogen.Field, protoPath, jsonPath string) {
+	name := f.Message.Desc.FullName()
+	if f.Desc.IsList() || q.visiting[name] {
+		return
+	}
+	q.visiting[name] = true
+	for _, nested := range f.Message.Fields {
+		q.field(nested, protoPath+".", jsonPath+".")
+	}
+	q.visiting[name] = false
+}
+
+// scalarJSON returns true if the JSON value of the basic type is a string,
+// number or boolean that can be written in a query string.
+func scalarJSON(t *doc.Basic) bool {
+	if t.Encoding == nil {
+		return false
+	}
+	switch t.Encoding.Type {
+	case "string", "number", "integer", "boolean":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package proto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// queryFile declares methods whose requests have fields of every kind.
+const queryFile = `
+name: "query.proto"
+package: "query"
+syntax: "proto3"
+dependency: ["google/api/annotations.proto", "google/api/field_behavior.proto", "google/protobuf/empty.proto", "google/protobuf/timestamp.proto"]
+message_type {
+  name: "Filter"
+  field { name: "query" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+  field { name: "and" number: 2 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".query.Filter" }
+}
+message_type {
+  name: "ListBooksRequest"
+  field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+  field {
+    name: "page_size" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL
+    options { [google.api.field_behavior]: REQUIRED }
+  }
+  field { name: "tags" number: 3 type: TYPE_STRING label: LABEL_REPEATED }
+  field {
+    name: "create_time" number: 4 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Timestamp"
+    options { [google.api.field_behavior]: OUTPUT_ONLY }
+  }
+  field { name: "after" number: 5 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Timestamp" }
+  field { name: "empty" number: 6 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Empty" }
+  field { name: "filter" number: 7 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".query.Filter" }
+  field { name: "labels" number: 8 type: TYPE_MESSAGE label: LABEL_REPEATED type_name: ".query.ListBooksRequest.LabelsEntry" }
+  field { name: "filters" number: 9 type: TYPE_MESSAGE label: LABEL_REPEATED type_name: ".query.Filter" }
+  field { name: "page_token" number: 10 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "token" }
+  nested_type {
+    name: "LabelsEntry"
+    field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+    field { name: "value" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL }
+    options { map_entry: true }
+  }
+}
+service {
+  name: "Books"
+  method {
+    name: "ListBooks" input_type: ".query.ListBooksRequest" output_type: ".google.protobuf.Empty"
+    options { [google.api.http] { get: "/v1/{name=shelves/*}/books" } }
+  }
+  method {
+    name: "SearchBooks" input_type: ".query.ListBooksRequest" output_type: ".google.protobuf.Empty"
+    options { [google.api.http] { post: "/v1/books:search" body: "filter" } }
+  }
+  method {
+    name: "CreateBook" input_type: ".query.ListBooksRequest" output_type: ".google.protobuf.Empty"
+    options { [google.api.http] { post: "/v1/books" body: "*" } }
+  }
+}
+`
+
+// queryNames returns the names of the query parameters of the first route of
+// each endpoint, suffixed by "[]" if repeated and "!" if required.
+func queryNames(endpoints []*doc.Endpoint) map[string]string {
+	names := make(map[string]string, len(endpoints))
+	for _, e := range endpoints {
+		var params []string
+		for _, p := range e.Routes[0].QueryParams {
+			name := p.Name
+			if p.Repeated {
+				name += "[]"
+			}
+			if p.Required {
+				name += "!"
+			}
+			params = append(params, name)
+		}
+		names[e.Name] = strings.Join(params, ",")
+	}
+	return names
+}
+
+func TestQueryParams(t *testing.T) {
+	p := newPlugin(t, queryFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "query.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"ListBooks", "pageSize!,tags[],after,filter.query,token"},
+		{"SearchBooks", "name,pageSize!,tags[],after,token"},
+		{"CreateBook", ""},
+	}
+	names := queryNames(pkg.Services[0].Endpoints)
+	for _, test := range tests {
+		if got := names[test.method]; got != test.want {
+			t.Errorf("query parameters of %s = %q, want %q", test.method, got, test.want)
+		}
+	}
+}
+
+func TestQueryParamsScalars(t *testing.T) {
+	p := newPlugin(t, queryFile)
+	var d diag.List
+	scalars := Scalars{
+		"query.Filter":          {Name: "Filter", Encoding: &doc.Encoding{Type: "string"}},
+		"google.protobuf.Empty": {Name: "Nothing", Encoding: &doc.Encoding{Type: "boolean"}},
+	}
+	pkg := ConvertFile(generatedFile(t, p, "query.proto"), scalars, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := "pageSize!,tags[],after,empty,filter,filters[],token"
+	if got := queryNames(pkg.Services[0].Endpoints)["ListBooks"]; got != want {
+		t.Errorf("query parameters of ListBooks = %q, want %q", got, want)
+	}
+	filter := pkg.Types["ListBooksRequest"].(*doc.Message).Fields[6]
+	if b, ok := filter.Type.(*doc.Basic); !ok || b.Name != "Filter" {
+		t.Errorf("type of filter = %#v, want the scalar", filter.Type)
+	}
+}
@@ -0,0 +1,202 @@
+package proto
+
+import (
+	"html"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// markdownBlocks renders the blocks as Markdown. Blocks are separated by blank
+// lines unless they are in the items of a tight list.
+func markdownBlocks(blocks []*doc.Block, tight bool) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		parts = append(parts, markdownBlock(b))
+	}
+	if tight {
+		return strings.Join(parts, "\n")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// markdownBlock renders the block as Markdown.
+func markdownBlock(b *doc.Block) string {
+	switch b.Kind {
+	case doc.BlockHeading:
+		return strings.Repeat("#", richHeadingLevel) + " " + markdownInlines(b.Text)
+	case doc.BlockCode:
+		fence := "```"
+		for strings.Contains(b.Code, fence) {
+			fence += "`"
+		}
+		return fence + "\n" + b.Code + "\n" + fence
+	case doc.BlockList:
+		tight := tightList(b)
+		items := make([]string, 0, len(b.Items))
+		for _, item := range b.Items {
+			marker := "-"
+			if b.Ordered {
+				marker = item.Number + "."
+			}
+			content := markdownBlocks(item.Blocks, tight)
+			if content == "" {
+				items = append(items, marker)
+				continue
+			}
+			// Lines continuing the item are indented past its marker.
+			lines := strings.Split(content, "\n")
+			for i := 1; i < len(lines); i++ {
+				if lines[i] != "" {
+					lines[i] = strings.Repeat(" ", len(marker)+1) + lines[i]
+				}
+			}
+			items = append(items, marker+" "+strings.Join(lines, "\n"))
+		}
+		if tight {
+			return strings.Join(items, "\n")
+		}
+		return strings.Join(items, "\n\n")
+	}
+	return markdownInlines(b.Text)
+}
+
+// markdownInlines renders the spans as Markdown.
+func markdownInlines(inlines []*doc.Inline) string {
+	var b strings.Builder
+	for i, in := range inlines {
+		switch in.Kind {
+		case doc.InlineCode:
+			b.WriteString(markdownCode(in.Text))
+		case doc.InlineLink:
+			if in.Text == in.URL && strings.Contains(in.URL, ":") {
+				b.WriteString("<" + in.URL + ">")
+				break
+			}
+			u := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(in.URL)
+			b.WriteString("[" + markdownText(in.Text, false) + "](" + u + ")")
+		default:
+			b.WriteString(markdownText(in.Text, i == 0))
+		}
+	}
+	return b.String()
+}
+
+// markdownCode renders the code as a code span, delimited by more backticks
+// than it contains in a row.
+func markdownCode(code string) string {
+	delim := "`"
+	for strings.Contains(code, delim) {
+		delim += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") ||
+		(strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.Trim(code, " ") != "") {
+		code = " " + code + " "
+	}
+	return delim + code + delim
+}
+
+// markdownText escapes the text so that it is not parsed as Markdown. If start
+// is true, the text is at the start of a line where it may also start a block.
+func markdownText(s string, start bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			b.WriteByte('\\')
+		case '_':
+			// Underscores within words do not emphasize.
+			if i == 0 || i == len(s)-1 || !isAlphanumeric(s[i-1]) || !isAlphanumeric(s[i+1]) {
+				b.WriteByte('\\')
+			}
+		case '&':
+			// Only entities such as "&amp;" are parsed.
+			if i+1 < len(s) && (isAlphanumeric(s[i+1]) || s[i+1] == '#') {
+				b.WriteByte('\\')
+			}
+		case '#', '>', '-', '+', '=':
+			if start && i == 0 {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// tightList returns true if the items of the list only contain a paragraph and
+// nested lists, which are rendered without blank lines between them.
+func tightList(list *doc.Block) bool {
+	for _, item := range list.Items {
+		paragraphs := 0
+		for _, b := range item.Blocks {
+			switch b.Kind {
+			case doc.BlockParagraph:
+				paragraphs++
+			case doc.BlockList:
+			default:
+				return false
+			}
+		}
+		if paragraphs > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// htmlBlocks renders the blocks as HTML. Paragraphs in the items of tight
+// lists are not wrapped in <p> elements.
+func htmlBlocks(blocks []*doc.Block, tight bool) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		switch block.Kind {
+		case doc.BlockParagraph:
+			if !tight {
+				b.WriteString("<p>" + htmlInlines(block.Text) + "</p>\n")
+				break
+			}
+			b.WriteString(htmlInlines(block.Text))
+			if i < len(blocks)-1 {
+				b.WriteByte('\n')
+			}
+		case doc.BlockHeading:
+			level := string(rune('0' + richHeadingLevel))
+			b.WriteString("<h" + level + ">" + htmlInlines(block.Text) + "</h" + level + ">\n")
+		case doc.BlockCode:
+			b.WriteString("<pre><code>" + html.EscapeString(block.Code) + "\n</code></pre>\n")
+		case doc.BlockList:
+			tag, start := "ul", ""
+			if block.Ordered {
+				tag = "ol"
+				if len(block.Items) > 0 && strings.TrimLeft(block.Items[0].Number, "0") != "1" {
+					start = ` start="` + html.EscapeString(block.Items[0].Number) + `"`
+				}
+			}
+			b.WriteString("<" + tag + start + ">\n")
+			itemsTight := tightList(block)
+			for _, item := range block.Items {
+				b.WriteString("<li>" + htmlBlocks(item.Blocks, itemsTight) + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+		}
+	}
+	return b.String()
+}
+
+// htmlInlines renders the spans as HTML.
+func htmlInlines(inlines []*doc.Inline) string {
+	var b strings.Builder
+	for _, in := range inlines {
+		switch in.Kind {
+		case doc.InlineCode:
+			b.WriteString("<code>" + html.EscapeString(in.Text) + "</code>")
+		case doc.InlineLink:
+			b.WriteString(`<a href="` + html.EscapeString(in.URL) + `">` + html.EscapeString(in.Text) + "</a>")
+		default:
+			b.WriteString(html.EscapeString(in.Text))
+		}
+	}
+	return b.String()
+}
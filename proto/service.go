@@ -2,44 +2,101 @@ package proto
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // ConvertService converts the provided protogen service to a doc service.
-func ConvertService(s *protogen.Service) *doc.Service {
+// Requests and responses are looked up in msgs, and messages in scalars are
+// documented as basic types. Problems found are reported to d.
+func ConvertService(s *protogen.Service, scalars Scalars, msgs Messages, d *diag.List) *doc.Service {
 	name := string(s.GoName)
 	desc := ConvertCommentSet(s.Comments)
 	endpoints := make([]*doc.Endpoint, 0, len(s.Methods))
 	for _, m := range s.Methods {
-		endpoint := ConvertMethod(m)
+		endpoint := ConvertMethod(m, scalars, msgs, d)
 		if endpoint != nil {
 			endpoints = append(endpoints, endpoint)
 		}
 	}
 	return &doc.Service{
-		Name:        name,
-		Description: desc.Long(name),
-		Endpoints:   endpoints,
+		Name:            name,
+		Description:     desc.Long(name),
+		RichDescription: desc.Rich(name),
+		Deprecation:     desc.Deprecation(s.Desc),
+		Endpoints:       endpoints,
 	}
 }
 
 // ConvertMethod converts the provided protogen method to a doc endpoint.
-// If the method is not an endpoint, nil is returned instead.
-func ConvertMethod(m *protogen.Method) *doc.Endpoint {
+// Methods without a google.api.http rule are documented as gRPC-only
+// endpoints. Its request and response are looked up in msgs and only converted
+// if they are not converted yet, documenting messages in scalars as basic
+// types. If the method is invalid, nil is returned instead. Problems found are
+// reported to d.
+func ConvertMethod(m *protogen.Method, scalars Scalars, msgs Messages, d *diag.List) *doc.Endpoint {
 	name := string(m.GoName)
 	desc := ConvertCommentSet(m.Comments)
 	opt := m.Desc.Options()
-	req, _ := ConvertMessage(m.Input)
-	resp, _ := ConvertMessage(m.Output)
+	req, _ := ConvertMessage(m.Input, scalars, msgs, d)
+	resp, _ := ConvertMessage(m.Output, scalars, msgs, d)
+	endpoint := &doc.Endpoint{
+		Name:              name,
+		Description:       desc.Long(name),
+		RichDescription:   desc.Rich(name),
+		Deprecation:       desc.Deprecation(m.Desc),
+		FullMethod:        fmt.Sprintf("/%s/%s", m.Parent.Desc.FullName(), m.Desc.Name()),
+		Request:           req,
+		RequestType:       string(m.Input.Desc.FullName()),
+		Response:          resp,
+		ResponseType:      string(m.Output.Desc.FullName()),
+		StreamingRequest:  m.Desc.IsStreamingClient(),
+		StreamingResponse: m.Desc.IsStreamingServer(),
+		Streaming:         streamingMode(m.Desc),
+		Pos:               pos(m.Desc),
+	}
 	// Parse HTTP verb and path.
 	if !proto.HasExtension(opt, annotations.E_Http) {
-		return nil
+		return endpoint
 	}
 	rule := proto.GetExtension(opt, annotations.E_Http).(*annotations.HttpRule)
+	rules := append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...)
+	for _, r := range rules {
+		route := convertRule(r, m, scalars, d)
+		if route == nil {
+			return nil
+		}
+		endpoint.Routes = append(endpoint.Routes, route)
+	}
+	endpoint.Method = endpoint.Routes[0].Method
+	endpoint.Path = endpoint.Routes[0].Path
+	endpoint.BodyField = endpoint.Routes[0].BodyField
+	// Use the type of the response body field as the response.
+	if rule.ResponseBody != "" {
+		f := findField(m.Output.Fields, rule.ResponseBody)
+		if f == nil {
+			d.Errorf(
+				pos(m.Desc), "cannot find response body field %q in %q for method %q",
+				rule.ResponseBody, m.Output.Desc.FullName(), m.Desc.FullName(),
+			)
+			return nil
+		}
+		endpoint.ResponseBodyField = f.Desc.JSONName()
+		endpoint.Response = fieldType(f, scalars, pos(f.Desc), d)
+	}
+	return endpoint
+}
+
+// convertRule converts a HTTP rule of the method to a route. If the rule is
+// invalid, nil is returned instead. Messages in scalars are documented as basic
+// types. Problems found are reported to d.
+func convertRule(rule *annotations.HttpRule, m *protogen.Method, scalars Scalars, d *diag.List) *doc.Route {
 	var method, path string
 	switch r := rule.Pattern.(type) {
 	case *annotations.HttpRule_Get:
@@ -60,34 +117,112 @@ func ConvertMethod(m *protogen.Method) *doc.Endpoint {
 	case *annotations.HttpRule_Custom:
 		method = r.Custom.Kind
 		path = r.Custom.Path
+	case nil:
+		d.Errorf(pos(m.Desc), "HTTP rule for method %q has no pattern", m.Desc.FullName())
+		return nil
 	default:
-		panic(fmt.Sprintf("unknown HTTP rule type: %T", rule.Pattern))
+		d.Errorf(pos(m.Desc), "unknown HTTP rule type %T for method %q", rule.Pattern, m.Desc.FullName())
+		return nil
 	}
 	// Find body field.
 	var bodyName string
-	if rule.Body != "" {
-		for _, v := range m.Input.Fields {
-			if v.GoName == rule.Body {
-				bodyName = v.Desc.JSONName()
-				break
-			}
+	switch rule.Body {
+	case "", "*":
+		bodyName = rule.Body
+	default:
+		f := findField(m.Input.Fields, rule.Body)
+		if f == nil {
+			d.Errorf(
+				pos(m.Desc), "cannot find body field %q in %q for method %q",
+				rule.Body, m.Input.Desc.FullName(), m.Desc.FullName(),
+			)
+			return nil
 		}
-		if bodyName == "" {
-			panic(fmt.Sprintf(
-				"cannot find body field %q for method %q",
-				rule.Body, m.Input.GoIdent.GoName,
-			))
+		bodyName = f.Desc.JSONName()
+	}
+	tmpl, err := ParseTemplate(path)
+	if err != nil {
+		d.Errorf(pos(m.Desc), "method %q: %v", m.Desc.FullName(), err)
+		return nil
+	}
+	route := &doc.Route{
+		Method:    method,
+		Path:      path,
+		BodyField: bodyName,
+		Template:  tmpl,
+	}
+	// Resolve path variables against the request.
+	for _, seg := range tmpl.Segments {
+		if seg.Kind != doc.SegmentVariable {
+			continue
 		}
+		v := seg.Variable
+		f, jsonPath := findFieldPath(m.Input, v.FieldPath)
+		if f == nil {
+			d.Errorf(
+				pos(m.Desc), "cannot find path variable field %q in %q for method %q",
+				v.FieldPath, m.Input.Desc.FullName(), m.Desc.FullName(),
+			)
+			return nil
+		}
+		v.JSONPath = jsonPath
+		desc := ConvertCommentSet(f.Comments)
+		route.PathParams = append(route.PathParams, &doc.Param{
+			Name:        v.FieldPath,
+			JSONPath:    jsonPath,
+			Pattern:     v.Pattern,
+			Description: desc.Short(f.GoName),
+			Deprecation: desc.Deprecation(f.Desc),
+			Type:        fieldType(f, scalars, pos(f.Desc), d),
+			Required:    required(f.Desc),
+		})
 	}
-	return &doc.Endpoint{
-		Name:              name,
-		Description:       desc.Long(name),
-		Method:            method,
-		Path:              path,
-		BodyField:         bodyName,
-		Request:           req,
-		Response:          resp,
-		StreamingRequest:  m.Desc.IsStreamingClient(),
-		StreamingResponse: m.Desc.IsStreamingServer(),
+	route.QueryParams = queryParams(m, route, scalars, d)
+	return route
+}
+
+// findFieldPath returns the field with the dot-separated path of proto names
+// in the message along with its path of JSON names, or nil if it is not found.
+func findFieldPath(m *protogen.Message, path string) (*protogen.Field, string) {
+	names := strings.Split(path, ".")
+	jsonNames := make([]string, 0, len(names))
+	for i, name := range names {
+		f := findField(m.Fields, name)
+		if f == nil {
+			return nil, ""
+		}
+		jsonNames = append(jsonNames, f.Desc.JSONName())
+		if i == len(names)-1 {
+			return f, strings.Join(jsonNames, ".")
+		}
+		if f.Message == nil || f.Desc.IsList() || f.Desc.IsMap() {
+			return nil, ""
+		}
+		m = f.Message
+	}
+	return nil, ""
+}
+
+// findField returns the field with the proto name, or nil if it is not found.
+func findField(fields []*protogen.Field, name string) *protogen.Field {
+	for _, f := range fields {
+		if string(f.Desc.Name()) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// streamingMode returns the streaming mode of the method.
+func streamingMode(m protoreflect.MethodDescriptor) string {
+	switch {
+	case m.IsStreamingClient() && m.IsStreamingServer():
+		return doc.StreamingBidirectional
+	case m.IsStreamingClient():
+		return doc.StreamingClient
+	case m.IsStreamingServer():
+		return doc.StreamingServer
+	default:
+		return doc.StreamingUnary
 	}
 }
@@ -0,0 +1,244 @@
+package proto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// shelfFile declares a message with an invalid example.
+const shelfFile = `
+name: "shelf.proto"
+package: "shelf"
+syntax: "proto3"
+message_type {
+  name: "Shelf"
+  field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+}
+source_code_info {
+  location { path: [4, 0, 2, 0] span: [3, 2, 20] leading_comments: " Example: shelves/1\n" }
+}
+`
+
+// libraryFile uses the message of shelfFile as a request and response.
+const libraryFile = `
+name: "library.proto"
+package: "library"
+syntax: "proto3"
+dependency: "shelf.proto"
+service {
+  name: "Library"
+  method { name: "GetShelf" input_type: ".shelf.Shelf" output_type: ".shelf.Shelf" }
+}
+`
+
+func TestConvertMethodReusesMessages(t *testing.T) {
+	p := newPlugin(t, shelfFile, libraryFile)
+	var d diag.List
+	msgs := make(Messages)
+	shelf := ConvertFile(generatedFile(t, p, "shelf.proto"), nil, msgs, &d)
+	library := ConvertFile(generatedFile(t, p, "library.proto"), nil, msgs, &d)
+	e := library.Services[0].Endpoints[0]
+	if e.Request != shelf.Types["Shelf"] || e.Response != shelf.Types["Shelf"] {
+		t.Errorf("request and response are not the converted message")
+	}
+	err, ok := d.Err().(diag.Errors)
+	if !ok || len(err) != 1 {
+		t.Fatalf("errors = %v, want the invalid example once", d.Err())
+	}
+	if want := "shelf.proto:4:3"; err[0].Pos.String() != want {
+		t.Errorf("error at %s, want %s", err[0].Pos, want)
+	}
+}
+
+// pathFile binds a nested field with a custom JSON name in the path.
+const pathFile = `
+name: "path.proto"
+package: "path"
+syntax: "proto3"
+dependency: "google/api/annotations.proto"
+message_type {
+  name: "Book"
+  field { name: "book_id" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "id" }
+}
+message_type {
+  name: "UpdateBookRequest"
+  field { name: "the_book" number: 1 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".path.Book" json_name: "theBook" }
+}
+service {
+  name: "Books"
+  method {
+    name: "UpdateBook" input_type: ".path.UpdateBookRequest" output_type: ".path.Book"
+    options { [google.api.http] { patch: "/v1/books/{the_book.book_id}" body: "the_book" } }
+  }
+}
+`
+
+func TestConvertRuleJSONPath(t *testing.T) {
+	p := newPlugin(t, pathFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "path.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	route := pkg.Services[0].Endpoints[0].Routes[0]
+	param := route.PathParams[0]
+	if param.Name != "the_book.book_id" || param.JSONPath != "theBook.id" {
+		t.Errorf("path parameter = %q with JSON path %q, want the_book.book_id with JSON path theBook.id", param.Name, param.JSONPath)
+	}
+	if v := route.Template.Segments[2].Variable; v.JSONPath != "theBook.id" {
+		t.Errorf("JSON path of variable = %q, want theBook.id", v.JSONPath)
+	}
+	if route.BodyField != "theBook" {
+		t.Errorf("body field = %q, want theBook", route.BodyField)
+	}
+}
+
+// brokenFile declares methods with invalid HTTP rules.
+const brokenFile = `
+name: "broken.proto"
+package: "broken"
+syntax: "proto3"
+dependency: "google/api/annotations.proto"
+message_type {
+  name: "Book"
+  field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+}
+service {
+  name: "Books"
+  method {
+    name: "NoPattern" input_type: ".broken.Book" output_type: ".broken.Book"
+    options { [google.api.http] { body: "*" } }
+  }
+  method {
+    name: "NoBody" input_type: ".broken.Book" output_type: ".broken.Book"
+    options { [google.api.http] { post: "/v1/books" body: "book" } }
+  }
+  method {
+    name: "BadPath" input_type: ".broken.Book" output_type: ".broken.Book"
+    options { [google.api.http] { get: "/v1/{name" } }
+  }
+  method {
+    name: "NoVariable" input_type: ".broken.Book" output_type: ".broken.Book"
+    options { [google.api.http] { get: "/v1/{title}" } }
+  }
+}
+source_code_info {
+  location { path: [6, 0, 2, 0] span: [10, 2, 12, 3] }
+  location { path: [6, 0, 2, 1] span: [13, 2, 15, 3] }
+  location { path: [6, 0, 2, 2] span: [16, 2, 18, 3] }
+  location { path: [6, 0, 2, 3] span: [19, 2, 21, 3] }
+}
+`
+
+func TestConvertMethodErrors(t *testing.T) {
+	p := newPlugin(t, brokenFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "broken.proto"), nil, make(Messages), &d)
+	if n := len(pkg.Services[0].Endpoints); n != 0 {
+		t.Errorf("%d endpoints converted, want none", n)
+	}
+	// Every error is collected in a single run.
+	want := []string{
+		`broken.proto:11:3: HTTP rule for method "broken.Books.NoPattern" has no pattern`,
+		`broken.proto:14:3: cannot find body field "book" in "broken.Book" for method "broken.Books.NoBody"`,
+		`broken.proto:17:3: method "broken.Books.BadPath": `,
+		`broken.proto:20:3: cannot find path variable field "title" in "broken.Book" for method "broken.Books.NoVariable"`,
+	}
+	err, _ := d.Err().(diag.Errors)
+	if len(err) != len(want) {
+		t.Fatalf("errors =\n%v\nwant %d errors", d.Err(), len(want))
+	}
+	for i, e := range err {
+		if !strings.HasPrefix(e.String(), want[i]) {
+			t.Errorf("error %d = %s, want %s", i, e, want[i])
+		}
+	}
+}
+
+// bindingFile binds a method to two routes and returns a repeated field of
+// its response.
+const bindingFile = `
+name: "binding.proto"
+package: "binding"
+syntax: "proto3"
+dependency: "google/api/annotations.proto"
+message_type {
+  name: "Book"
+  field { name: "title" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "title" }
+}
+message_type {
+  name: "CreateBookRequest"
+  field { name: "book" number: 1 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".binding.Book" json_name: "book" }
+  field { name: "draft_book" number: 2 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".binding.Book" json_name: "draftBook" }
+}
+message_type {
+  name: "ListBooksResponse"
+  field { name: "books" number: 1 type: TYPE_MESSAGE label: LABEL_REPEATED type_name: ".binding.Book" json_name: "books" }
+}
+service {
+  name: "Books"
+  method {
+    name: "CreateBook" input_type: ".binding.CreateBookRequest" output_type: ".binding.Book"
+    options {
+      [google.api.http] {
+        post: "/v1/books" body: "book"
+        additional_bindings { post: "/v1/drafts" body: "draft_book" }
+      }
+    }
+  }
+  method {
+    name: "ListBooks" input_type: ".binding.Book" output_type: ".binding.ListBooksResponse"
+    options { [google.api.http] { get: "/v1/books" response_body: "books" } }
+  }
+  method {
+    name: "ListAuthors" input_type: ".binding.Book" output_type: ".binding.ListBooksResponse"
+    options { [google.api.http] { get: "/v1/authors" response_body: "authors" } }
+  }
+}
+source_code_info {
+  location { path: [6, 0, 2, 2] span: [30, 2, 33, 3] }
+}
+`
+
+func TestConvertMethodBindings(t *testing.T) {
+	p := newPlugin(t, bindingFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "binding.proto"), nil, make(Messages), &d)
+	endpoints := pkg.Services[0].Endpoints
+	if len(endpoints) != 2 {
+		t.Fatalf("%d endpoints converted, want CreateBook and ListBooks", len(endpoints))
+	}
+
+	create := endpoints[0]
+	var routes []string
+	for _, r := range create.Routes {
+		routes = append(routes, r.Method+" "+r.Path+" "+r.BodyField)
+	}
+	if got, want := strings.Join(routes, ", "), "POST /v1/books book, POST /v1/drafts draftBook"; got != want {
+		t.Errorf("routes of CreateBook = %s, want %s", got, want)
+	}
+	if create.BodyField != "book" {
+		t.Errorf("body field of CreateBook = %q, want the body field of its first route", create.BodyField)
+	}
+
+	list := endpoints[1]
+	if list.ResponseBodyField != "books" {
+		t.Errorf("response body field of ListBooks = %q, want books", list.ResponseBodyField)
+	}
+	arr, ok := list.Response.(*doc.Array)
+	if !ok {
+		t.Fatalf("response of ListBooks = %#v, want an array", list.Response)
+	}
+	if ref, ok := arr.Value.(*doc.Ref); !ok || ref.Name != "binding.Book" {
+		t.Errorf("response of ListBooks = array of %#v, want array of binding.Book", arr.Value)
+	}
+
+	err, _ := d.Err().(diag.Errors)
+	want := `binding.proto:31:3: cannot find response body field "authors" in "binding.ListBooksResponse" for method "binding.Books.ListAuthors"`
+	if len(err) != 1 || err[0].String() != want {
+		t.Errorf("errors = %v, want %s", d.Err(), want)
+	}
+}
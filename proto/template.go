@@ -0,0 +1,165 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// ParseTemplate parses the HTTP path template of a google.api.http rule such
+// as "/v1/{name=projects/*/books/*}:publish". The grammar is:
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+//
+// The root path "/" is also accepted and has no segments.
+func ParseTemplate(path string) (*doc.PathTemplate, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path template %q does not start with '/'", path)
+	}
+	if path == "/" {
+		return &doc.PathTemplate{Segments: []*doc.PathSegment{}}, nil
+	}
+	p := &templateParser{input: path, pos: 1}
+	segments, err := p.segments(false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %q: %w", path, err)
+	}
+	tmpl := &doc.PathTemplate{
+		Segments: segments,
+	}
+	if p.consume(':') {
+		tmpl.Verb = p.literal()
+		if tmpl.Verb == "" {
+			return nil, fmt.Errorf("invalid path template %q: empty verb", path)
+		}
+	}
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("invalid path template %q: unexpected %q at offset %d", path, p.input[p.pos], p.pos)
+	}
+	return tmpl, nil
+}
+
+// templateParser is the state of ParseTemplate.
+type templateParser struct {
+	input string
+	pos   int
+}
+
+// peek returns the next byte, or 0 if the end of input is reached.
+func (p *templateParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// consume consumes the next byte if it is c.
+func (p *templateParser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// segments parses a list of segments separated by '/'. Variables are not
+// allowed if inVariable is true.
+func (p *templateParser) segments(inVariable bool) ([]*doc.PathSegment, error) {
+	var segments []*doc.PathSegment
+	for {
+		seg, err := p.segment(inVariable)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+		if !p.consume('/') {
+			return segments, nil
+		}
+	}
+}
+
+// segment parses a single segment.
+func (p *templateParser) segment(inVariable bool) (*doc.PathSegment, error) {
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "**"):
+		p.pos += 2
+		return &doc.PathSegment{Kind: doc.SegmentDeepWildcard, Value: "**"}, nil
+	case p.consume('*'):
+		return &doc.PathSegment{Kind: doc.SegmentWildcard, Value: "*"}, nil
+	case p.peek() == '{':
+		if inVariable {
+			return nil, fmt.Errorf("nested variable at offset %d", p.pos)
+		}
+		p.pos++
+		return p.variable()
+	}
+	lit := p.literal()
+	if lit == "" {
+		return nil, fmt.Errorf("empty segment at offset %d", p.pos)
+	}
+	return &doc.PathSegment{Kind: doc.SegmentLiteral, Value: lit}, nil
+}
+
+// variable parses a variable after the opening brace.
+func (p *templateParser) variable() (*doc.PathSegment, error) {
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("=}", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	fieldPath := p.input[start:p.pos]
+	for _, ident := range strings.Split(fieldPath, ".") {
+		if !isIdent(ident) {
+			return nil, fmt.Errorf("invalid field path %q at offset %d", fieldPath, start)
+		}
+	}
+	v := &doc.PathVariable{
+		FieldPath: fieldPath,
+		Segments:  []*doc.PathSegment{{Kind: doc.SegmentWildcard, Value: "*"}},
+	}
+	if p.consume('=') {
+		patternStart := p.pos
+		segments, err := p.segments(true)
+		if err != nil {
+			return nil, err
+		}
+		v.Segments = segments
+		v.Pattern = p.input[patternStart:p.pos]
+	} else {
+		v.Pattern = "*"
+	}
+	if !p.consume('}') {
+		return nil, fmt.Errorf("unterminated variable %q at offset %d", fieldPath, start)
+	}
+	return &doc.PathSegment{Kind: doc.SegmentVariable, Variable: v}, nil
+}
+
+// literal parses a literal, which continues until a reserved character.
+func (p *templateParser) literal() string {
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("/:{}=*", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// isIdent returns true if s is a valid proto identifier.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
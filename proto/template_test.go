@@ -0,0 +1,78 @@
+package proto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// segmentsString formats the segments with their kind, such as
+// "lit:v1/var:name(lit:books/wild:*)".
+func segmentsString(segs []*doc.PathSegment) string {
+	parts := make([]string, 0, len(segs))
+	for _, seg := range segs {
+		switch seg.Kind {
+		case doc.SegmentLiteral:
+			parts = append(parts, "lit:"+seg.Value)
+		case doc.SegmentWildcard, doc.SegmentDeepWildcard:
+			parts = append(parts, "wild:"+seg.Value)
+		case doc.SegmentVariable:
+			v := seg.Variable
+			parts = append(parts, "var:"+v.FieldPath+"="+v.Pattern+"("+segmentsString(v.Segments)+")")
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		verb string
+	}{
+		{path: "/", want: ""},
+		{path: "/v1/books", want: "lit:v1/lit:books"},
+		{path: "/v1/{name}", want: "lit:v1/var:name=*(wild:*)"},
+		{path: "/v1/{book.name=shelves/*/books/*}", want: "lit:v1/var:book.name=shelves/*/books/*(lit:shelves/wild:*/lit:books/wild:*)"},
+		{path: "/v1/{name=files/**}", want: "lit:v1/var:name=files/**(lit:files/wild:**)"},
+		{path: "/v1/*/books/**", want: "lit:v1/wild:*/lit:books/wild:**"},
+		{path: "/v1/{name}:publish", want: "lit:v1/var:name=*(wild:*)", verb: "publish"},
+		{path: "/v1/books:batchGet", want: "lit:v1/lit:books", verb: "batchGet"},
+	}
+	for _, test := range tests {
+		tmpl, err := ParseTemplate(test.path)
+		if err != nil {
+			t.Errorf("ParseTemplate(%q): %v", test.path, err)
+			continue
+		}
+		if got := segmentsString(tmpl.Segments); got != test.want || tmpl.Verb != test.verb {
+			t.Errorf("ParseTemplate(%q) = %q with verb %q, want %q with verb %q", test.path, got, tmpl.Verb, test.want, test.verb)
+		}
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"", "does not start with '/'"},
+		{"v1/books", "does not start with '/'"},
+		{"/v1//books", "empty segment at offset 4"},
+		{"/v1/", "empty segment at offset 4"},
+		{"/:verb", "empty segment at offset 1"},
+		{"/v1/books:", "empty verb"},
+		{"/v1/{name", "unterminated variable"},
+		{"/v1/{na-me}", `invalid field path "na-me"`},
+		{"/v1/{book.}", `invalid field path "book."`},
+		{"/v1/{a=shelves/{b}}", "nested variable"},
+		{"/v1/books}", `unexpected '}'`},
+	}
+	for _, test := range tests {
+		_, err := ParseTemplate(test.path)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("ParseTemplate(%q) error = %v, want %q", test.path, err, test.err)
+		}
+	}
+}
@@ -1,27 +1,169 @@
 package proto
 
 import (
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
 	"github.com/chanbakjsd/protoc-gen-doc/doc"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// wellKnownTypes are types defined that are common but not a basic type.
-var wellKnownTypes = map[string]doc.Type{
-	"google.protobuf.Any":       &doc.Basic{"Any", ""},
-	"google.protobuf.Duration":  &doc.Basic{"Duration", ""},
-	"google.protobuf.Empty":     &doc.Basic{"Empty", ""},
-	"google.protobuf.Value":     &doc.Basic{"JSON", ""},
-	"google.protobuf.List":      &doc.Basic{"JSON List", ""},
-	"google.protobuf.Struct":    &doc.Basic{"JSON Struct", ""},
-	"google.protobuf.Timestamp": &doc.Basic{"Timestamp", ""},
+// scalarTypes are the basic types of scalar fields by name along with their
+// representation in proto3 JSON.
+var scalarTypes = map[string]doc.Basic{
+	"Boolean": {Name: "Boolean", Encoding: &doc.Encoding{
+		Type: "boolean",
+	}},
+	"Integer": {Name: "Integer", Encoding: &doc.Encoding{
+		Type:   "integer",
+		Format: "int32",
+		Alternates: []*doc.Encoding{
+			{Type: "string", Format: "int32", Description: "Decimal string."},
+		},
+	}},
+	"Unsigned Integer": {Name: "Unsigned Integer", Encoding: &doc.Encoding{
+		Type:   "integer",
+		Format: "uint32",
+		Alternates: []*doc.Encoding{
+			{Type: "string", Format: "uint32", Description: "Decimal string."},
+		},
+	}},
+	"Integer(64)": {Name: "Integer(64)", Encoding: &doc.Encoding{
+		Type:        "string",
+		Format:      "int64",
+		Description: "Decimal string.",
+		Alternates: []*doc.Encoding{
+			{Type: "integer", Format: "int64", Description: "Number, which loses precision beyond 2^53."},
+		},
+	}},
+	"Unsigned Integer(64)": {Name: "Unsigned Integer(64)", Encoding: &doc.Encoding{
+		Type:        "string",
+		Format:      "uint64",
+		Description: "Decimal string.",
+		Alternates: []*doc.Encoding{
+			{Type: "integer", Format: "uint64", Description: "Number, which loses precision beyond 2^53."},
+		},
+	}},
+	"Float(32)": {Name: "Float(32)", Encoding: &doc.Encoding{
+		Type:   "number",
+		Format: "float",
+		Alternates: []*doc.Encoding{
+			{Type: "string", Enum: []string{"NaN", "Infinity", "-Infinity"}, Description: "Special values."},
+			{Type: "string", Format: "float", Description: "Decimal string."},
+		},
+	}},
+	"Float(64)": {Name: "Float(64)", Encoding: &doc.Encoding{
+		Type:   "number",
+		Format: "double",
+		Alternates: []*doc.Encoding{
+			{Type: "string", Enum: []string{"NaN", "Infinity", "-Infinity"}, Description: "Special values."},
+			{Type: "string", Format: "double", Description: "Decimal string."},
+		},
+	}},
+	"String": {Name: "String", Encoding: &doc.Encoding{
+		Type: "string",
+	}},
+	"Bytes": {Name: "Bytes", Encoding: &doc.Encoding{
+		Type:            "string",
+		ContentEncoding: "base64",
+		Description:     "Standard base64 with padding.",
+		Alternates: []*doc.Encoding{
+			{Type: "string", ContentEncoding: "base64url", Description: "URL-safe base64, with or without padding."},
+		},
+	}},
 }
 
-// fieldType returns the type of a field.
-func fieldType(f *protogen.Field) doc.Type {
+// scalarType returns the scalar basic type with the name.
+func scalarType(name string) *doc.Basic {
+	t := scalarTypes[name]
+	return &t
+}
+
+// nullable returns the wrapper type of the scalar basic type with the name,
+// which is represented as the wrapped value or null.
+func nullable(name string) doc.Basic {
+	t := scalarTypes[name]
+	enc := *t.Encoding
+	enc.Nullable = true
+	t.Name = "Nullable " + name
+	t.Encoding = &enc
+	return t
+}
+
+// wellKnownTypes are types defined that are common but not a basic type,
+// along with their representation in proto3 JSON. They are copied for each
+// use as basic types may be modified later.
+var wellKnownTypes = map[string]doc.Basic{
+	"google.protobuf.Any": {Name: "Any", Encoding: &doc.Encoding{
+		Type:        "object",
+		Description: `Object with an "@type" field containing the type URL of the message, such as "type.googleapis.com/google.protobuf.Duration", and the fields of the message. Messages with a special JSON representation are placed in a "value" field instead.`,
+	}},
+	"google.protobuf.Duration": {Name: "Duration", Encoding: &doc.Encoding{
+		Type:        "string",
+		Format:      "duration",
+		Description: `Seconds with up to 9 fractional digits followed by "s", such as "1.5s".`,
+	}},
+	"google.protobuf.Empty": {Name: "Empty", Encoding: &doc.Encoding{
+		Type:        "object",
+		Description: "Empty object.",
+	}},
+	"google.protobuf.FieldMask": {Name: "Field Mask", Encoding: &doc.Encoding{
+		Type:        "string",
+		Format:      "field-mask",
+		Description: `Field paths in lowerCamelCase joined by commas, such as "user.displayName,photo".`,
+	}},
+	"google.protobuf.ListValue": {Name: "JSON List", Encoding: &doc.Encoding{
+		Type:        "array",
+		Description: "Array of any JSON values.",
+	}},
+	"google.protobuf.NullValue": {Name: "Null", Encoding: &doc.Encoding{
+		Type:        "null",
+		Description: "JSON null.",
+	}},
+	"google.protobuf.Struct": {Name: "JSON Struct", Encoding: &doc.Encoding{
+		Type:        "object",
+		Description: "Object with any JSON values.",
+	}},
+	"google.protobuf.Timestamp": {Name: "Timestamp", Encoding: &doc.Encoding{
+		Type:        "string",
+		Format:      "date-time",
+		Description: `RFC 3339 date-time in UTC with up to 9 fractional digits, such as "1972-01-01T10:00:20.021Z".`,
+	}},
+	"google.protobuf.Value": {Name: "JSON", Encoding: &doc.Encoding{
+		Description: "Any JSON value.",
+	}},
+	// Wrappers are represented as the wrapped value, or null if unset.
+	"google.protobuf.BoolValue":   nullable("Boolean"),
+	"google.protobuf.BytesValue":  nullable("Bytes"),
+	"google.protobuf.DoubleValue": nullable("Float(64)"),
+	"google.protobuf.FloatValue":  nullable("Float(32)"),
+	"google.protobuf.Int32Value":  nullable("Integer"),
+	"google.protobuf.Int64Value":  nullable("Integer(64)"),
+	"google.protobuf.StringValue": nullable("String"),
+	"google.protobuf.UInt32Value": nullable("Unsigned Integer"),
+	"google.protobuf.UInt64Value": nullable("Unsigned Integer(64)"),
+}
+
+// Scalars maps the full names of messages to the basic types that they are
+// documented as wherever they are used instead of as references, as
+// configured by the user. They are used like wellKnownTypes.
+type Scalars map[string]doc.Basic
+
+// basicMessage returns the basic type that the message with the full name is
+// documented as, if any.
+func (s Scalars) basicMessage(fullName string) (doc.Basic, bool) {
+	if t, ok := s[fullName]; ok {
+		return t, true
+	}
+	t, ok := wellKnownTypes[fullName]
+	return t, ok
+}
+
+// fieldType returns the type of a field declared at p, where messages in
+// scalars are basic types. Problems found are reported to d.
+func fieldType(f *protogen.Field, scalars Scalars, p diag.Pos, d *diag.List) doc.Type {
 	if f.Desc.IsMap() {
-		key := fieldType(f.Message.Fields[0])
-		value := fieldType(f.Message.Fields[1])
+		key := fieldType(f.Message.Fields[0], scalars, p, d)
+		value := fieldType(f.Message.Fields[1], scalars, p, d)
 		return &doc.Map{
 			Key:   key,
 			Value: value,
@@ -30,38 +172,44 @@ func fieldType(f *protogen.Field) doc.Type {
 	var typ doc.Type
 	switch f.Desc.Kind() {
 	case protoreflect.BoolKind:
-		typ = &doc.Basic{"Boolean", ""}
-	case protoreflect.Int32Kind, protoreflect.Sint32Kind,
-		protoreflect.Sfixed32Kind, protoreflect.Fixed32Kind:
-		typ = &doc.Basic{"Integer", ""}
-	case protoreflect.Uint32Kind:
-		typ = &doc.Basic{"Unsigned Integer", ""}
-	case protoreflect.Int64Kind, protoreflect.Sint64Kind,
-		protoreflect.Sfixed64Kind, protoreflect.Fixed64Kind:
-		typ = &doc.Basic{"Integer(64)", ""}
-	case protoreflect.Uint64Kind:
-		typ = &doc.Basic{"Unsigned Integer(64)", ""}
+		typ = scalarType("Boolean")
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		typ = scalarType("Integer")
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		typ = scalarType("Unsigned Integer")
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		typ = scalarType("Integer(64)")
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		typ = scalarType("Unsigned Integer(64)")
 	case protoreflect.FloatKind:
-		typ = &doc.Basic{"Float(32)", ""}
+		typ = scalarType("Float(32)")
 	case protoreflect.DoubleKind:
-		typ = &doc.Basic{"Float(64)", ""}
+		typ = scalarType("Float(64)")
 	case protoreflect.StringKind:
-		typ = &doc.Basic{"String", ""}
+		typ = scalarType("String")
 	case protoreflect.BytesKind:
-		typ = &doc.Basic{"Bytes", ""}
+		typ = scalarType("Bytes")
 	case protoreflect.EnumKind:
-		typ = &doc.Ref{string(f.Desc.Enum().FullName())}
-	case protoreflect.MessageKind:
-		fullName := string(f.Desc.Message().FullName())
-		typ = &doc.Ref{fullName}
-		if t, ok := wellKnownTypes[fullName]; ok {
-			typ = t
-		}
+		typ = namedType(string(f.Desc.Enum().FullName()), scalars, p)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		typ = namedType(string(f.Desc.Message().FullName()), scalars, p)
 	default:
-		panic("Unknown protobuf type: " + f.Desc.Kind().String())
+		d.Errorf(p, "unknown protobuf type %s for field %q", f.Desc.Kind(), f.Desc.FullName())
+		typ = &doc.Basic{Name: f.Desc.Kind().String()}
 	}
+	setExample(typ, string(f.Desc.Name()))
 	if f.Desc.IsList() {
-		typ = &doc.Array{typ}
+		typ = &doc.Array{Value: typ}
 	}
 	return typ
 }
+
+// namedType returns the type of a field referencing the enum or message with
+// the full name, which is a basic type for well-known types and messages in
+// scalars.
+func namedType(fullName string, scalars Scalars, p diag.Pos) doc.Type {
+	if t, ok := scalars.basicMessage(fullName); ok {
+		return &t
+	}
+	return &doc.Ref{Name: fullName, Pos: p}
+}
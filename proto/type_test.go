@@ -0,0 +1,164 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// wellKnownFile declares a field of every well-known type with a special
+// JSON representation.
+const wellKnownFile = `
+name: "known.proto"
+package: "known"
+syntax: "proto3"
+dependency: "google/protobuf/any.proto"
+dependency: "google/protobuf/duration.proto"
+dependency: "google/protobuf/empty.proto"
+dependency: "google/protobuf/field_mask.proto"
+dependency: "google/protobuf/struct.proto"
+dependency: "google/protobuf/timestamp.proto"
+dependency: "google/protobuf/wrappers.proto"
+message_type {
+  name: "Known"
+  field { name: "any" number: 1 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Any" json_name: "any" }
+  field { name: "duration" number: 2 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Duration" json_name: "duration" }
+  field { name: "timestamp" number: 3 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Timestamp" json_name: "timestamp" }
+  field { name: "mask" number: 4 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.FieldMask" json_name: "mask" }
+  field { name: "null" number: 5 type: TYPE_ENUM label: LABEL_OPTIONAL type_name: ".google.protobuf.NullValue" json_name: "null" }
+  field { name: "list" number: 6 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.ListValue" json_name: "list" }
+  field { name: "struct" number: 7 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Struct" json_name: "struct" }
+  field { name: "value" number: 8 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Value" json_name: "value" }
+  field { name: "bool" number: 9 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.BoolValue" json_name: "bool" }
+  field { name: "bytes" number: 10 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.BytesValue" json_name: "bytes" }
+  field { name: "double" number: 11 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.DoubleValue" json_name: "double" }
+  field { name: "float" number: 12 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.FloatValue" json_name: "float" }
+  field { name: "int32" number: 13 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Int32Value" json_name: "int32" }
+  field { name: "int64" number: 14 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Int64Value" json_name: "int64" }
+  field { name: "string" number: 15 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.StringValue" json_name: "string" }
+  field { name: "uint32" number: 16 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.UInt32Value" json_name: "uint32" }
+  field { name: "uint64" number: 17 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.UInt64Value" json_name: "uint64" }
+  field { name: "empty" number: 18 type: TYPE_MESSAGE label: LABEL_OPTIONAL type_name: ".google.protobuf.Empty" json_name: "empty" }
+}
+`
+
+// encodingText returns the JSON type, format, content encoding and
+// nullability of the encoding, such as "string/int64/base64/nullable".
+func encodingText(e *doc.Encoding) string {
+	s := fmt.Sprintf("%s/%s/%s", e.Type, e.Format, e.ContentEncoding)
+	if e.Nullable {
+		s += "/nullable"
+	}
+	return s
+}
+
+func TestWellKnownTypes(t *testing.T) {
+	p := newPlugin(t, wellKnownFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "known.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"any":       "Any object//",
+		"duration":  "Duration string/duration/",
+		"timestamp": "Timestamp string/date-time/",
+		"mask":      "Field Mask string/field-mask/",
+		"null":      "Null null//",
+		"list":      "JSON List array//",
+		"struct":    "JSON Struct object//",
+		"value":     "JSON //",
+		"bool":      "Nullable Boolean boolean///nullable",
+		"bytes":     "Nullable Bytes string//base64/nullable",
+		"double":    "Nullable Float(64) number/double//nullable",
+		"float":     "Nullable Float(32) number/float//nullable",
+		"int32":     "Nullable Integer integer/int32//nullable",
+		"int64":     "Nullable Integer(64) string/int64//nullable",
+		"string":    "Nullable String string///nullable",
+		"uint32":    "Nullable Unsigned Integer integer/uint32//nullable",
+		"uint64":    "Nullable Unsigned Integer(64) string/uint64//nullable",
+		"empty":     "Empty object//",
+	}
+	fields := pkg.Types["Known"].(*doc.Message).Fields
+	if len(fields) != len(want) {
+		t.Fatalf("%d fields converted, want %d", len(fields), len(want))
+	}
+	for _, f := range fields {
+		b, ok := f.Type.(*doc.Basic)
+		if !ok {
+			t.Errorf("type of %s = %#v, want a basic type", f.Name, f.Type)
+			continue
+		}
+		if got := b.Name + " " + encodingText(b.Encoding); got != want[f.Name] {
+			t.Errorf("type of %s = %s, want %s", f.Name, got, want[f.Name])
+		}
+	}
+}
+
+// scalarFile declares a field of every scalar type.
+const scalarFile = `
+name: "scalar.proto"
+package: "scalar"
+syntax: "proto3"
+message_type {
+  name: "Scalars"
+  field { name: "int64" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL json_name: "int64" }
+  field { name: "sfixed64" number: 2 type: TYPE_SFIXED64 label: LABEL_OPTIONAL json_name: "sfixed64" }
+  field { name: "fixed64" number: 3 type: TYPE_FIXED64 label: LABEL_OPTIONAL json_name: "fixed64" }
+  field { name: "int32" number: 4 type: TYPE_SINT32 label: LABEL_OPTIONAL json_name: "int32" }
+  field { name: "bytes" number: 5 type: TYPE_BYTES label: LABEL_OPTIONAL json_name: "bytes" }
+  field { name: "double" number: 6 type: TYPE_DOUBLE label: LABEL_OPTIONAL json_name: "double" }
+  field { name: "float" number: 7 type: TYPE_FLOAT label: LABEL_OPTIONAL json_name: "float" }
+  field { name: "bool" number: 8 type: TYPE_BOOL label: LABEL_OPTIONAL json_name: "bool" }
+}
+`
+
+// alternatesText returns the alternate encodings, with their allowed strings.
+func alternatesText(e *doc.Encoding) string {
+	parts := make([]string, 0, len(e.Alternates))
+	for _, alt := range e.Alternates {
+		s := encodingText(alt)
+		if len(alt.Enum) > 0 {
+			s += "=" + strings.Join(alt.Enum, "|")
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestScalarEncodings(t *testing.T) {
+	p := newPlugin(t, scalarFile)
+	var d diag.List
+	pkg := ConvertFile(generatedFile(t, p, "scalar.proto"), nil, make(Messages), &d)
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		encoding   string
+		alternates string
+	}{
+		// 64-bit integers are strings as numbers lose precision.
+		{"string/int64/", "integer/int64/"},
+		{"string/int64/", "integer/int64/"},
+		{"string/uint64/", "integer/uint64/"},
+		{"integer/int32/", "string/int32/"},
+		{"string//base64", "string//base64url"},
+		{"number/double/", "string//=NaN|Infinity|-Infinity, string/double/"},
+		{"number/float/", "string//=NaN|Infinity|-Infinity, string/float/"},
+		{"boolean//", ""},
+	}
+	fields := pkg.Types["Scalars"].(*doc.Message).Fields
+	for i, test := range tests {
+		f := fields[i]
+		enc := f.Type.(*doc.Basic).Encoding
+		if got := encodingText(enc); got != test.encoding {
+			t.Errorf("encoding of %s = %s, want %s", f.Name, got, test.encoding)
+		}
+		if got := alternatesText(enc); got != test.alternates {
+			t.Errorf("alternates of %s = %s, want %s", f.Name, got, test.alternates)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// DeprecatedElement is an element of the documentation that is deprecated.
+type DeprecatedElement struct {
+	// Kind is the kind of the element, such as "endpoint" or "field".
+	Kind string
+	// Name is the fully qualified name of the element.
+	Name string
+	// Message is the deprecation message of the element.
+	Message string
+}
+
+// Deprecations returns every deprecated element in the tag in the order they
+// are documented.
+func Deprecations(tag *doc.Tag) []DeprecatedElement {
+	var c deprecationCollector
+	c.seen = make(map[string]bool)
+	for _, pkg := range tag.Packages {
+		c.add("package", pkg.ID, pkg.Deprecation)
+		for _, srv := range pkg.Services {
+			c.add("service", pkg.ID+"."+srv.Name, srv.Deprecation)
+			for _, e := range srv.Endpoints {
+				c.add("endpoint", pkg.ID+"."+srv.Name+"."+e.Name, e.Deprecation)
+				// Requests and responses may not be named types of the
+				// package, or may be declared in another package.
+				c.typ(e.RequestType, e.Request)
+				if e.ResponseBodyField == "" {
+					c.typ(e.ResponseType, e.Response)
+				}
+			}
+		}
+		for _, name := range sortedTypeNames(pkg) {
+			c.typ(pkg.ID+"."+name, pkg.Types[name])
+		}
+	}
+	return c.elements
+}
+
+// deprecationCollector is the state of Deprecations.
+type deprecationCollector struct {
+	elements []DeprecatedElement
+	// seen is the set of messages and enums that are already collected.
+	seen map[string]bool
+}
+
+// add adds the element if it is deprecated.
+func (c *deprecationCollector) add(kind, name string, d doc.Deprecation) {
+	if !d.Deprecated {
+		return
+	}
+	c.elements = append(c.elements, DeprecatedElement{
+		Kind:    kind,
+		Name:    name,
+		Message: d.DeprecationMessage,
+	})
+}
+
+// typ adds the type with the fully qualified name and its members if they are
+// deprecated.
+func (c *deprecationCollector) typ(name string, t doc.Type) {
+	switch t := t.(type) {
+	case *doc.Message:
+		if c.seen[name] {
+			return
+		}
+		c.seen[name] = true
+		c.add("message", name, t.Deprecation)
+		for _, f := range t.Fields {
+			c.add("field", name+"."+f.Name, f.Deprecation)
+		}
+		for _, o := range t.Oneofs {
+			c.add("oneof", name+"."+o.Name, o.Deprecation)
+		}
+	case *doc.Enum:
+		if c.seen[name] {
+			return
+		}
+		c.seen[name] = true
+		c.add("enum", name, t.Deprecation)
+		for _, v := range t.Values {
+			c.add("enum value", name+"."+v.Value, v.Deprecation)
+		}
+	}
+}
+
+// DeprecationReport renders the deprecated elements of every section as a
+// single Markdown document.
+func DeprecationReport(w io.Writer, tags map[string]*doc.Tag) error {
+	m := &markdown{}
+	m.heading(1, "", "Deprecations")
+	for _, section := range SortedSections(tags) {
+		tag := tags[section]
+		m.heading(2, "", tagTitle(section, tag))
+		elements := Deprecations(tag)
+		if len(elements) == 0 {
+			m.paragraph("No deprecated elements.")
+			continue
+		}
+		m.printf("| Element | Kind | Message |")
+		m.printf("| --- | --- | --- |")
+		for _, e := range elements {
+			m.printf("| `%s` | %s | %s |", e.Name, e.Kind, cell(e.Message))
+		}
+		m.printf("")
+	}
+	_, err := io.WriteString(w, strings.TrimSpace(m.b.String())+"\n")
+	return err
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+func TestDeprecations(t *testing.T) {
+	deprecated := doc.Deprecation{Deprecated: true, DeprecationMessage: "Do not use."}
+	request := &doc.Message{
+		Name:        "Request",
+		Deprecation: deprecated,
+		Fields:      []*doc.Field{{Name: "old", Deprecation: deprecated}},
+	}
+	status := &doc.Enum{
+		Name: "Order.Status",
+		Values: []*doc.EnumVal{
+			{Value: "STATUS_UNSPECIFIED"},
+			{Value: "STATUS_LEGACY", Deprecation: deprecated},
+		},
+	}
+	tag := &doc.Tag{Packages: []*doc.Package{{
+		ID: "shop",
+		Services: []*doc.Service{{
+			Name: "Shop",
+			Endpoints: []*doc.Endpoint{
+				{
+					Name:         "Get",
+					Deprecation:  deprecated,
+					Request:      request,
+					RequestType:  "common.Request",
+					Response:     &doc.Basic{Name: "String"},
+					ResponseType: "shop.GetResponse",
+				},
+				// The request is only collected once.
+				{Name: "List", Request: request, RequestType: "common.Request"},
+			},
+		}},
+		Types: map[string]doc.Type{
+			"Order.Status": status,
+		},
+	}}}
+	want := []DeprecatedElement{
+		{Kind: "endpoint", Name: "shop.Shop.Get", Message: "Do not use."},
+		{Kind: "message", Name: "common.Request", Message: "Do not use."},
+		{Kind: "field", Name: "common.Request.old", Message: "Do not use."},
+		{Kind: "enum value", Name: "shop.Order.Status.STATUS_LEGACY", Message: "Do not use."},
+	}
+	got := Deprecations(tag)
+	if len(got) != len(want) {
+		t.Fatalf("Deprecations = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("element %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
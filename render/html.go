@@ -0,0 +1,413 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// HTMLSite renders every tag as a page of a static HTML site. The site does
+// not reference any external assets.
+type HTMLSite struct {
+	tags     map[string]*doc.Tag
+	idx      *Index
+	sections []string
+}
+
+// NewHTMLSite creates a site from the provided tags.
+func NewHTMLSite(idx *Index, tags map[string]*doc.Tag) *HTMLSite {
+	return &HTMLSite{
+		tags:     tags,
+		idx:      idx,
+		sections: SortedSections(tags),
+	}
+}
+
+// Pages returns the file names of all pages in the site.
+func (s *HTMLSite) Pages() []string {
+	pages := []string{"index.html"}
+	for _, section := range s.sections {
+		pages = append(pages, section+".html")
+	}
+	return pages
+}
+
+// Render renders the page with the specified file name.
+func (s *HTMLSite) Render(w io.Writer, page string) error {
+	section := strings.TrimSuffix(page, ".html")
+	data := htmlPage{
+		Site:    s,
+		Section: section,
+		Tag:     s.tags[section],
+	}
+	if page == "index.html" {
+		data.Section = ""
+		data.Tag = nil
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// htmlPage is the data passed to the page template.
+type htmlPage struct {
+	Site    *HTMLSite
+	Section string
+	Tag     *doc.Tag
+}
+
+// htmlArgs passes a value to a nested template along with the page.
+type htmlArgs struct {
+	Page  htmlPage
+	Value interface{}
+}
+
+// htmlNavEntry is an entry in the navigation sidebar.
+type htmlNavEntry struct {
+	Section string
+	Title   string
+	Tag     *doc.Tag
+}
+
+// Nav returns the navigation sidebar entries ordered by weight.
+func (p htmlPage) Nav() []htmlNavEntry {
+	entries := make([]htmlNavEntry, 0, len(p.Site.sections))
+	for _, section := range p.Site.sections {
+		tag := p.Site.tags[section]
+		entries = append(entries, htmlNavEntry{
+			Section: section,
+			Title:   tagTitle(section, tag),
+			Tag:     tag,
+		})
+	}
+	return entries
+}
+
+// Title returns the title of the page.
+func (p htmlPage) Title() string {
+	if p.Tag == nil {
+		return "API Reference"
+	}
+	return tagTitle(p.Section, p.Tag)
+}
+
+// TypeName returns the HTML name of the type, linking to named types.
+func (p htmlPage) TypeName(t doc.Type) template.HTML {
+	switch t := t.(type) {
+	case *doc.Array:
+		return "Array of " + p.TypeName(t.Value)
+	case *doc.Map:
+		return "Map of " + p.TypeName(t.Key) + " to " + p.TypeName(t.Value)
+	case *doc.Ref:
+		loc, ok := p.Site.idx.lookup(t.Name)
+		if !ok {
+			return "<code>" + template.HTML(template.HTMLEscapeString(t.Name)) + "</code>"
+		}
+		href := loc.section + ".html#" + t.Name
+		return `<a href="` + template.HTML(template.HTMLEscapeString(href)) + `">` +
+			template.HTML(template.HTMLEscapeString(loc.name)) + "</a>"
+	case doc.NamedType:
+		return template.HTML(template.HTMLEscapeString(t.TypeName()))
+	}
+	return ""
+}
+
+// FieldNotes returns the notes on the presence and behaviors of the field, if
+// any.
+func (p htmlPage) FieldNotes(f *doc.Field) string {
+	return fieldNotes(f)
+}
+
+// Parent returns the HTML link to the message a type in the package is
+// nested in.
+func (p htmlPage) Parent(pkg *doc.Package, parent string) template.HTML {
+	return p.TypeName(&doc.Ref{Name: pkg.ID + "." + parent})
+}
+
+// paragraphs splits the text into paragraphs.
+func paragraphs(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n\n")
+}
+
+// description returns the description as HTML, using its rich text if there
+// is one.
+func description(text string, rich *doc.RichText) template.HTML {
+	if rich != nil {
+		return template.HTML(strings.TrimSpace(rich.HTML))
+	}
+	var b strings.Builder
+	for i, p := range paragraphs(text) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("<p>" + template.HTMLEscapeString(p) + "</p>")
+	}
+	return template.HTML(b.String())
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"args": func(p htmlPage, v interface{}) htmlArgs {
+		return htmlArgs{Page: p, Value: v}
+	},
+	"paragraphs":     paragraphs,
+	"description":    description,
+	"typeAnchor":     typeAnchor,
+	"endpointAnchor": endpointAnchor,
+	"sortedTypes":    sortedTypeNames,
+	"fieldRanges":    fieldRanges,
+	"message": func(t doc.Type) *doc.Message {
+		msg, _ := t.(*doc.Message)
+		return msg
+	},
+	"enum": func(t doc.Type) *doc.Enum {
+		e, _ := t.(*doc.Enum)
+		return e
+	},
+}).Parse(htmlSource))
+
+const htmlSource = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; line-height: 1.5; }
+nav { position: fixed; top: 0; bottom: 0; left: 0; width: 17rem; overflow-y: auto; padding: 1rem; box-sizing: border-box; background: #f6f8fa; border-right: 1px solid #d0d7de; font-size: 0.9rem; }
+nav ul { list-style: none; padding-left: 0.8rem; margin: 0.2rem 0; }
+nav > ul { padding-left: 0; }
+nav .current > a { font-weight: bold; }
+main { margin-left: 17rem; padding: 1rem 2rem 4rem; max-width: 60rem; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+code { font-family: ui-monospace, Consolas, monospace; font-size: 0.9em; background: #eff1f3; padding: 0.1em 0.3em; border-radius: 4px; }
+table { border-collapse: collapse; margin: 0.5rem 0 1rem; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.route { font-family: ui-monospace, Consolas, monospace; }
+.method { font-weight: bold; margin-right: 0.5em; }
+.body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #59636e; }
+.deprecated { color: #9a6700; }
+.endpoint, .type { border-top: 1px solid #d0d7de; padding-top: 0.5rem; }
+</style>
+</head>
+<body>
+<nav>
+<p><a href="index.html">API Reference</a></p>
+<ul>
+{{- range .Nav}}
+<li{{if eq .Section $.Section}} class="current"{{end}}><a href="{{.Section}}.html">{{.Title}}</a>
+{{- if eq .Section $.Section}}
+<ul>
+{{- range .Tag.Packages}}{{$pkg := .}}
+<li><a href="#{{.ID}}">{{.ID}}</a>
+<ul>
+{{- range .Services}}{{$srv := .}}
+{{- range .Endpoints}}
+<li><a href="#{{endpointAnchor $pkg $srv .}}">{{.Name}}</a></li>
+{{- end}}
+{{- end}}
+{{- range sortedTypes .}}
+<li><a href="#{{typeAnchor $pkg .}}">{{.}}</a></li>
+{{- end}}
+</ul>
+</li>
+{{- end}}
+</ul>
+{{- end}}
+</li>
+{{- end}}
+</ul>
+</nav>
+<main>
+{{- if not .Tag}}
+<h1>API Reference</h1>
+<ul>
+{{- range .Nav}}
+<li><a href="{{.Section}}.html">{{.Title}}</a></li>
+{{- end}}
+</ul>
+{{- else}}
+<h1>{{.Title}}</h1>
+{{- range paragraphs .Tag.Preamble}}
+<p>{{.}}</p>
+{{- end}}
+{{- range .Tag.Packages}}{{$pkg := .}}
+<h2 id="{{.ID}}">Package <code>{{.ID}}</code></h2>
+{{- template "deprecation" .Deprecation}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+{{- if .Files}}
+<p>Declared in {{range $i, $f := .Files}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}.</p>
+{{- end}}
+{{- range .Services}}{{$srv := .}}
+<h3 id="{{$pkg.ID}}.{{.Name}}">Service <code>{{.Name}}</code></h3>
+{{- template "deprecation" .Deprecation}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+{{- range .Endpoints}}
+<section class="endpoint" id="{{endpointAnchor $pkg $srv .}}">
+<h4>{{.Name}}</h4>
+{{- range .Routes}}
+<p class="route"><span class="method">{{.Method}}</span>{{.Path}}
+{{- if eq .BodyField "*"}} <span class="body">with the request as the body</span>
+{{- else if .BodyField}} <span class="body">with body field <code>{{.BodyField}}</code></span>
+{{- end}}</p>
+{{- else}}
+<p class="route"><span class="method">gRPC</span>{{.FullMethod}}</p>
+{{- end}}
+{{- template "deprecation" .Deprecation}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+{{- if or .ResponseBodyField (ne .Streaming "unary")}}
+<ul>
+{{- if .ResponseBodyField}}
+<li>Response body field: <code>{{.ResponseBodyField}}</code></li>
+{{- end}}
+{{- if ne .Streaming "unary"}}
+<li>Streaming: {{.Streaming}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- $routes := .Routes}}
+{{- range .Routes}}
+{{- if .PathParams}}
+<h5>Path parameters{{if gt (len $routes) 1}} of <code>{{.Method}} {{.Path}}</code>{{end}}</h5>
+{{template "params" (args $ .PathParams)}}
+{{- end}}
+{{- if .QueryParams}}
+<h5>Query parameters{{if gt (len $routes) 1}} of <code>{{.Method}} {{.Path}}</code>{{end}}</h5>
+{{template "params" (args $ .QueryParams)}}
+{{- end}}
+{{- end}}
+<h5>Request</h5>
+{{template "inline" (args $ .Request)}}
+<h5>Response</h5>
+{{template "inline" (args $ .Response)}}
+</section>
+{{- end}}
+{{- end}}
+{{- if .Types}}
+<h3>Types</h3>
+{{- range sortedTypes .}}{{$name := .}}{{$t := index $pkg.Types .}}
+<section class="type" id="{{typeAnchor $pkg .}}">
+{{- with message $t}}
+<h4>Message <code>{{$name}}</code></h4>
+{{- if .Parent}}
+<p>Nested in {{$.Parent $pkg .Parent}}.</p>
+{{- end}}
+{{- template "deprecation" .Deprecation}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+{{- if .Recursive}}
+<p><em>Recursive type: it can contain itself.</em></p>
+{{- end}}
+{{template "fields" (args $ .Fields)}}
+{{template "oneofs" .Oneofs}}
+{{template "wire" .}}
+{{- end}}
+{{- with enum $t}}
+<h4>Enum <code>{{$name}}</code></h4>
+{{- if .Parent}}
+<p>Nested in {{$.Parent $pkg .Parent}}.</p>
+{{- end}}
+{{- template "deprecation" .Deprecation}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+<table>
+<tr><th>Value</th><th>Number</th><th>Description</th></tr>
+{{- range .Values}}
+<tr><td><code>{{.DisplayName}}</code>{{if .Default}} (default){{end}}</td><td>{{.Number}}</td><td>{{template "deprecated" .Deprecation}}{{.Description}}
+{{- if .Aliases}}{{if .Description}}.{{end}} Aliases: {{range $i, $a := .Aliases}}{{if $i}}, {{end}}<code>{{$a}}</code>{{end}}.{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</section>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+{{define "inline"}}
+{{- with message .Value}}
+{{- with description .Description .RichDescription}}
+{{.}}
+{{- end}}
+{{template "fields" (args $.Page .Fields)}}
+{{template "oneofs" .Oneofs}}
+{{template "wire" .}}
+{{- else}}
+<p>Type: {{$.Page.TypeName .Value}}</p>
+{{- end}}
+{{- end}}
+{{define "params"}}
+<table>
+<tr><th>Parameter</th><th>Type</th><th>Description</th></tr>
+{{- range .Value}}
+<tr><td><code>{{.Name}}</code></td><td>{{$.Page.TypeName .Type}}
+{{- if and .Required .Repeated}} (required, repeatable)
+{{- else if .Required}} (required)
+{{- else if .Repeated}} (repeatable){{end}}
+{{- if and .Pattern (ne .Pattern "*")}} matching <code>{{.Pattern}}</code>{{end}}</td><td>{{template "deprecated" .Deprecation}}{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{define "deprecation"}}
+{{- if .Deprecated}}
+<p class="deprecated"><strong>Deprecated.</strong>{{with .DeprecationMessage}} {{.}}{{end}}</p>
+{{- end}}
+{{- end}}
+{{define "deprecated"}}
+{{- if .Deprecated}}<strong>Deprecated.</strong>{{with .DeprecationMessage}} {{.}}{{end}} {{end}}
+{{- end}}
+{{define "oneofs"}}
+{{- if .}}
+<p><strong>Oneof groups</strong></p>
+<ul>
+{{- range .}}
+<li><code>{{.Name}}</code>: {{template "deprecated" .Deprecation}}at most one of {{range $i, $f := .Fields}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}} can be set.
+{{- if .Description}} {{.Description}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{define "wire"}}
+{{- if or .ReservedRanges .ReservedNames .ExtensionRanges}}
+<ul>
+{{- with .ReservedRanges}}
+<li>Reserved numbers: {{fieldRanges .}}</li>
+{{- end}}
+{{- with .ReservedNames}}
+<li>Reserved names: {{range $i, $n := .}}{{if $i}}, {{end}}<code>{{$n}}</code>{{end}}</li>
+{{- end}}
+{{- with .ExtensionRanges}}
+<li>Extension ranges: {{fieldRanges .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{define "fields"}}
+{{- if .Value}}
+<table>
+<tr><th>Field</th><th>Number</th><th>Type</th><th>Description</th></tr>
+{{- range .Value}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Number}}</td><td>{{$.Page.TypeName .Type}}
+{{- with $.Page.FieldNotes .}} ({{.}}){{end}}</td><td>{{template "deprecated" .Deprecation}}{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No fields.</p>
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	htmlID   = regexp.MustCompile(`\sid="([^"]*)"`)
+	htmlHref = regexp.MustCompile(`\shref="([^"]*)"`)
+	htmlSrc  = regexp.MustCompile(`\ssrc=|<link\b|<script\b|url\(`)
+)
+
+func TestHTMLSite(t *testing.T) {
+	tags := libraryTags()
+	site := NewHTMLSite(NewIndex(tags), tags)
+	if got, want := strings.Join(site.Pages(), ","), "index.html,library.html,common.html"; got != want {
+		t.Fatalf("pages = %s, want %s", got, want)
+	}
+	pages := make(map[string]string)
+	ids := make(map[string]map[string]bool)
+	for _, page := range site.Pages() {
+		var b strings.Builder
+		if err := site.Render(&b, page); err != nil {
+			t.Fatal(err)
+		}
+		pages[page] = b.String()
+		ids[page] = make(map[string]bool)
+		for _, m := range htmlID.FindAllStringSubmatch(b.String(), -1) {
+			ids[page][m[1]] = true
+		}
+	}
+
+	for page, out := range pages {
+		// The site does not load any asset, let alone an external one.
+		if loc := htmlSrc.FindStringIndex(out); loc != nil {
+			t.Errorf("%s loads an asset: %s", page, out[loc[0]:loc[1]])
+		}
+		// Every link points to a page of the site and an element in it.
+		for _, m := range htmlHref.FindAllStringSubmatch(out, -1) {
+			target, anchor := m[1], ""
+			if i := strings.IndexByte(target, '#'); i >= 0 {
+				target, anchor = target[:i], target[i+1:]
+			}
+			if target == "" {
+				target = page
+			}
+			if _, ok := pages[target]; !ok {
+				t.Errorf("%s links to %s outside of the site", page, m[1])
+				continue
+			}
+			if anchor != "" && !ids[target][anchor] {
+				t.Errorf("%s links to %s, which has no element with the ID", page, m[1])
+			}
+		}
+	}
+
+	for _, want := range []string{
+		`<li class="current"><a href="library.html">Library</a>`,
+		`<p>Type: <a href="library.html#lib.Book">Book</a></p>`,
+		`<section class="type" id="lib.Book">`,
+		`<p><em>Recursive type: it can contain itself.</em></p>`,
+		`<tr><td><code>title</code></td><td>1</td><td>String</td><td>The title.</td></tr>`,
+		// Map, repeated and recursive fields.
+		`<td>Map of String to <a href="library.html#lib.Book.Format">Book.Format</a></td>`,
+		`<td>Array of <a href="common.html#common.Author">Author</a></td>`,
+		`<td><a href="library.html#lib.Book">Book</a></td>`,
+		// Nested enum.
+		`<section class="type" id="lib.Book.Format">` + "\n" + `<h4>Enum <code>Book.Format</code></h4>` + "\n" +
+			`<p>Nested in <a href="library.html#lib.Book">Book</a>.</p>`,
+		`<tr><td><code>FORMAT_UNSPECIFIED</code> (default)</td><td>0</td><td></td></tr>`,
+	} {
+		if !strings.Contains(pages["library.html"], want) {
+			t.Errorf("library.html does not contain %q:\n%s", want, pages["library.html"])
+		}
+	}
+	if !strings.Contains(pages["common.html"], `<section class="type" id="common.Author">`) {
+		t.Errorf("common.html does not document Author:\n%s", pages["common.html"])
+	}
+}
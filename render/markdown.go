@@ -0,0 +1,340 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// Markdown renders the tag with the provided section name as a Markdown
+// document. References to types documented in other sections link to
+// "<section>.md".
+func Markdown(w io.Writer, idx *Index, section string, tag *doc.Tag) error {
+	m := &markdown{
+		idx:     idx,
+		section: section,
+	}
+	m.tag(tag)
+	_, err := io.WriteString(w, m.b.String())
+	return err
+}
+
+// markdown is the state of a Markdown render.
+type markdown struct {
+	b       strings.Builder
+	idx     *Index
+	section string
+}
+
+// printf writes a formatted line to the output.
+func (m *markdown) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&m.b, format, args...)
+	m.b.WriteByte('\n')
+}
+
+// paragraph writes a paragraph of text if it is not empty.
+func (m *markdown) paragraph(text string) {
+	if text == "" {
+		return
+	}
+	m.printf("%s\n", text)
+}
+
+// description writes the description, using its rich text if there is one.
+func (m *markdown) description(text string, rich *doc.RichText) {
+	m.paragraph(markdownDescription(text, rich))
+}
+
+// heading writes a heading with an optional anchor.
+func (m *markdown) heading(level int, anchor, title string) {
+	if anchor != "" {
+		m.printf(`<a name="%s"></a>`, anchor)
+		m.printf("")
+	}
+	m.printf("%s %s\n", strings.Repeat("#", level), title)
+}
+
+func (m *markdown) tag(tag *doc.Tag) {
+	m.heading(1, "", tagTitle(m.section, tag))
+	m.paragraph(strings.TrimSpace(tag.Preamble))
+	for _, pkg := range tag.Packages {
+		m.pkg(pkg)
+	}
+}
+
+func (m *markdown) pkg(pkg *doc.Package) {
+	m.heading(2, pkg.ID, "Package `"+pkg.ID+"`")
+	m.deprecation(pkg.Deprecation)
+	m.description(pkg.Description, pkg.RichDescription)
+	if len(pkg.Files) > 0 {
+		m.paragraph("Declared in `" + strings.Join(pkg.Files, "`, `") + "`.")
+	}
+	for _, srv := range pkg.Services {
+		m.heading(3, pkg.ID+"."+srv.Name, "Service `"+srv.Name+"`")
+		m.deprecation(srv.Deprecation)
+		m.description(srv.Description, srv.RichDescription)
+		for _, e := range srv.Endpoints {
+			m.endpoint(pkg, srv, e)
+		}
+	}
+	if len(pkg.Types) == 0 {
+		return
+	}
+	m.heading(3, "", "Types")
+	for _, name := range sortedTypeNames(pkg) {
+		m.namedType(pkg, name, pkg.Types[name])
+	}
+}
+
+func (m *markdown) endpoint(pkg *doc.Package, srv *doc.Service, e *doc.Endpoint) {
+	m.heading(4, endpointAnchor(pkg, srv, e), e.Name)
+	for _, r := range e.Routes {
+		m.printf("- %s", routeSummary(r))
+	}
+	if len(e.Routes) == 0 {
+		m.printf("- gRPC `%s`", e.FullMethod)
+	}
+	m.printf("")
+	m.deprecation(e.Deprecation)
+	m.description(e.Description, e.RichDescription)
+	var details []string
+	if e.ResponseBodyField != "" {
+		details = append(details, "Response body field: `"+e.ResponseBodyField+"`")
+	}
+	if e.Streaming != doc.StreamingUnary {
+		details = append(details, "Streaming: "+e.Streaming)
+	}
+	for _, d := range details {
+		m.printf("- %s", d)
+	}
+	if len(details) > 0 {
+		m.printf("")
+	}
+	for _, r := range e.Routes {
+		suffix := ""
+		if len(e.Routes) > 1 {
+			suffix = " of `" + r.Method + " " + r.Path + "`"
+		}
+		if len(r.PathParams) > 0 {
+			m.printf("**Path parameters**%s\n", suffix)
+			m.params(r.PathParams)
+		}
+		if len(r.QueryParams) > 0 {
+			m.printf("**Query parameters**%s\n", suffix)
+			m.params(r.QueryParams)
+		}
+	}
+	m.printf("**Request**\n")
+	m.inlineType(e.Request)
+	m.printf("**Response**\n")
+	m.inlineType(e.Response)
+}
+
+// routeSummary returns the method, path and body of the route in Markdown.
+func routeSummary(r *doc.Route) string {
+	s := "`" + r.Method + " " + r.Path + "`"
+	switch r.BodyField {
+	case "":
+	case "*":
+		s += " with the request as the body"
+	default:
+		s += " with body field `" + r.BodyField + "`"
+	}
+	return s
+}
+
+// inlineType writes the type of a request or response in place.
+func (m *markdown) inlineType(t doc.Type) {
+	msg, ok := t.(*doc.Message)
+	if !ok {
+		m.paragraph("Type: " + m.typeName(t))
+		return
+	}
+	m.description(msg.Description, msg.RichDescription)
+	m.fields(msg.Fields)
+	m.oneofs(msg.Oneofs)
+	m.wire(msg)
+}
+
+func (m *markdown) namedType(pkg *doc.Package, name string, t doc.Type) {
+	switch t := t.(type) {
+	case *doc.Message:
+		m.heading(4, typeAnchor(pkg, name), "Message `"+name+"`")
+		m.parent(pkg, t.Parent)
+		m.deprecation(t.Deprecation)
+		m.description(t.Description, t.RichDescription)
+		if t.Recursive {
+			m.paragraph("_Recursive type: it can contain itself._")
+		}
+		m.fields(t.Fields)
+		m.oneofs(t.Oneofs)
+		m.wire(t)
+	case *doc.Enum:
+		m.heading(4, typeAnchor(pkg, name), "Enum `"+name+"`")
+		m.parent(pkg, t.Parent)
+		m.deprecation(t.Deprecation)
+		m.description(t.Description, t.RichDescription)
+		m.printf("| Value | Number | Description |")
+		m.printf("| --- | --- | --- |")
+		for _, v := range t.Values {
+			value := "`" + v.DisplayName + "`"
+			if v.Default {
+				value += " (default)"
+			}
+			description := v.Description
+			if len(v.Aliases) > 0 {
+				if description != "" {
+					description += ". "
+				}
+				description += "Aliases: `" + strings.Join(v.Aliases, "`, `") + "`."
+			}
+			description = strings.TrimSpace(deprecationPrefix(v.Deprecation) + description)
+			m.printf("| %s | %d | %s |", value, v.Number, cell(description))
+		}
+		m.printf("")
+	}
+}
+
+// deprecation writes the deprecation notice if the element is deprecated.
+func (m *markdown) deprecation(d doc.Deprecation) {
+	if !d.Deprecated {
+		return
+	}
+	m.paragraph(strings.TrimSpace("**Deprecated.** " + escapeHTML(d.DeprecationMessage)))
+}
+
+// deprecationPrefix returns the deprecation notice to be placed before the
+// description of an element in a table, or an empty string if it is not
+// deprecated.
+func deprecationPrefix(d doc.Deprecation) string {
+	if !d.Deprecated {
+		return ""
+	}
+	return strings.TrimSpace("**Deprecated.** "+d.DeprecationMessage) + " "
+}
+
+// parent writes the message a type is nested in if there is one.
+func (m *markdown) parent(pkg *doc.Package, parent string) {
+	if parent == "" {
+		return
+	}
+	m.paragraph("Nested in " + m.typeName(&doc.Ref{Name: pkg.ID + "." + parent}) + ".")
+}
+
+// params writes the table of parameters.
+func (m *markdown) params(params []*doc.Param) {
+	m.printf("| Parameter | Type | Description |")
+	m.printf("| --- | --- | --- |")
+	for _, p := range params {
+		typ := m.typeName(p.Type)
+		switch {
+		case p.Required && p.Repeated:
+			typ += " (required, repeatable)"
+		case p.Required:
+			typ += " (required)"
+		case p.Repeated:
+			typ += " (repeatable)"
+		}
+		if p.Pattern != "" && p.Pattern != "*" {
+			typ += " matching `" + p.Pattern + "`"
+		}
+		m.printf("| `%s` | %s | %s |", p.Name, typ, cell(deprecationPrefix(p.Deprecation)+p.Description))
+	}
+	m.printf("")
+}
+
+// fields writes the table of fields.
+func (m *markdown) fields(fields []*doc.Field) {
+	if len(fields) == 0 {
+		m.paragraph("No fields.")
+		return
+	}
+	m.printf("| Field | Number | Type | Description |")
+	m.printf("| --- | --- | --- | --- |")
+	for _, f := range fields {
+		typ := m.typeName(f.Type)
+		if note := fieldNotes(f); note != "" {
+			typ += " (" + note + ")"
+		}
+		m.printf("| `%s` | %d | %s | %s |", f.Name, f.Number, typ, cell(deprecationPrefix(f.Deprecation)+f.Description))
+	}
+	m.printf("")
+}
+
+// oneofs writes the list of oneof groups.
+func (m *markdown) oneofs(oneofs []*doc.Oneof) {
+	if len(oneofs) == 0 {
+		return
+	}
+	m.printf("**Oneof groups**\n")
+	for _, o := range oneofs {
+		s := "- `" + o.Name + "`: " + escapeHTML(deprecationPrefix(o.Deprecation)) +
+			"at most one of `" + strings.Join(o.Fields, "`, `") + "` can be set."
+		if o.Description != "" {
+			s += " " + strings.ReplaceAll(escapeHTML(o.Description), "\n", " ")
+		}
+		m.printf("%s", s)
+	}
+	m.printf("")
+}
+
+// wire writes the reserved field numbers and names and the extension ranges of
+// the message.
+func (m *markdown) wire(msg *doc.Message) {
+	var lines []string
+	if len(msg.ReservedRanges) > 0 {
+		lines = append(lines, "Reserved numbers: "+fieldRanges(msg.ReservedRanges))
+	}
+	if len(msg.ReservedNames) > 0 {
+		lines = append(lines, "Reserved names: `"+strings.Join(msg.ReservedNames, "`, `")+"`")
+	}
+	if len(msg.ExtensionRanges) > 0 {
+		lines = append(lines, "Extension ranges: "+fieldRanges(msg.ExtensionRanges))
+	}
+	for _, l := range lines {
+		m.printf("- %s", l)
+	}
+	if len(lines) > 0 {
+		m.printf("")
+	}
+}
+
+// typeName returns the name of the type, linking to named types.
+func (m *markdown) typeName(t doc.Type) string {
+	switch t := t.(type) {
+	case *doc.Array:
+		return "Array of " + m.typeName(t.Value)
+	case *doc.Map:
+		return "Map of " + m.typeName(t.Key) + " to " + m.typeName(t.Value)
+	case *doc.Ref:
+		loc, ok := m.idx.lookup(t.Name)
+		if !ok {
+			return "`" + t.Name + "`"
+		}
+		link := "#" + t.Name
+		if loc.section != m.section {
+			link = loc.section + ".md" + link
+		}
+		return "[" + loc.name + "](" + link + ")"
+	case doc.NamedType:
+		return t.TypeName()
+	}
+	return ""
+}
+
+// cell escapes the text to be placed in a table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(escapeHTML(s), "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// htmlEscaper escapes the characters that start HTML tags and entities.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeHTML escapes plain text written to Markdown so that HTML in it is
+// displayed instead of interpreted.
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,169 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/config"
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"github.com/chanbakjsd/protoc-gen-doc/generate"
+)
+
+func TestMarkdown(t *testing.T) {
+	tags := libraryTags()
+	var b strings.Builder
+	if err := Markdown(&b, NewIndex(tags), "library", tags["library"]); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# Library\n",
+		"<a name=\"lib.Library.GetBook\"></a>\n\n#### GetBook\n\n- `GET /v1/book`\n",
+		"**Response**\n\nType: [Book](#lib.Book)\n",
+		"<a name=\"lib.Book\"></a>\n\n#### Message `Book`\n\n_Recursive type: it can contain itself._\n",
+		"| `title` | 1 | String | The title. |\n",
+		// Map, repeated and recursive fields.
+		"| `formats` | 2 | Map of String to [Book.Format](#lib.Book.Format) |  |\n",
+		"| `authors` | 3 | Array of [Author](common.md#common.Author) |  |\n",
+		"| `sequel` | 4 | [Book](#lib.Book) |  |\n",
+		// Nested enum.
+		"<a name=\"lib.Book.Format\"></a>\n\n#### Enum `Book.Format`\n\nNested in [Book](#lib.Book).\n",
+		"| `FORMAT_UNSPECIFIED` (default) | 0 |  |\n| `FORMAT_PAPERBACK` | 1 |  |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "common.Author\"") {
+		t.Errorf("type of another section is documented:\n%s", out)
+	}
+}
+
+// variantTags returns the tags of a section filtering field behaviors, where
+// an order with a nested enum and an OUTPUT_ONLY field is only used by a
+// request.
+func variantTags(t *testing.T) map[string]*doc.Tag {
+	t.Helper()
+	pkg := &doc.Package{
+		ID: "shop",
+		Types: map[string]doc.Type{
+			"Order": &doc.Message{
+				Name: "Order",
+				Fields: []*doc.Field{
+					{Name: "id", Number: 1, Type: &doc.Basic{Name: "String"}, Behaviors: []string{doc.BehaviorOutputOnly}},
+					{Name: "status", Number: 2, Type: &doc.Ref{Name: "shop.Order.Status"}},
+				},
+			},
+			"Order.Status": &doc.Enum{
+				Name:   "Order.Status",
+				Parent: "Order",
+				Values: []*doc.EnumVal{{Value: "OPEN", DisplayName: "OPEN"}},
+			},
+		},
+		Services: []*doc.Service{{
+			Name: "Shop",
+			Endpoints: []*doc.Endpoint{{
+				Name: "CreateOrder",
+				Request: &doc.Message{
+					Name:   "CreateOrderRequest",
+					Fields: []*doc.Field{{Name: "order", Number: 1, Type: &doc.Ref{Name: "shop.Order"}}},
+				},
+				Response: &doc.Message{Name: "CreateOrderResponse"},
+			}},
+		}},
+	}
+	cfg := &config.Config{
+		Sections: map[string]config.Section{
+			"shop": {Packages: []string{"shop"}, FilterFieldBehavior: true},
+		},
+	}
+	var d diag.List
+	tags, err := generate.Tags(cfg, []*doc.Package{pkg}, &d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return tags
+}
+
+func TestMarkdownNestedInVariant(t *testing.T) {
+	tags := variantTags(t)
+	var b strings.Builder
+	if err := Markdown(&b, NewIndex(tags), "shop", tags["shop"]); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"[Order-Input](#shop.Order-Input)",
+		"Nested in [Order](#shop.Order).",
+		`<a name="shop.Order"></a>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdownEscapesHTML(t *testing.T) {
+	pkg := &doc.Package{
+		ID: "shop",
+		Types: map[string]doc.Type{
+			"Book": &doc.Message{
+				Name: "Book",
+				Fields: []*doc.Field{{
+					Name:        "title",
+					Type:        &doc.Basic{Name: "String"},
+					Description: "<script>alert(1)</script> | a & b",
+				}},
+				Oneofs: []*doc.Oneof{{Name: "kind", Fields: []string{"title"}, Description: "<b>bold</b>"}},
+			},
+			"Status": &doc.Enum{
+				Name: "Status",
+				Values: []*doc.EnumVal{{
+					Value:       "OPEN",
+					DisplayName: "OPEN",
+					Description: `<img src="x" onerror="alert(1)">`,
+					Deprecation: doc.Deprecation{Deprecated: true, DeprecationMessage: "Use <i>CLOSED</i>."},
+				}},
+			},
+		},
+		Services: []*doc.Service{{
+			Name: "Shop",
+			Endpoints: []*doc.Endpoint{{
+				Name:    "GetBook",
+				Request: &doc.Message{Name: "GetBookRequest"},
+				Routes: []*doc.Route{{
+					Method:     "GET",
+					Path:       "/v1/books/{name}",
+					PathParams: []*doc.Param{{Name: "name", Type: &doc.Basic{Name: "String"}, Description: "<iframe src=x>"}},
+				}},
+				Response: &doc.Ref{Name: "shop.Book"},
+			}},
+		}},
+	}
+	tags := map[string]*doc.Tag{"shop": {Packages: []*doc.Package{pkg}}}
+	var b strings.Builder
+	if err := Markdown(&b, NewIndex(tags), "shop", tags["shop"]); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, tag := range []string{"<script", "<img", "<iframe", "<b>", "<i>"} {
+		if strings.Contains(out, tag) {
+			t.Errorf("output contains %s:\n%s", tag, out)
+		}
+	}
+	for _, want := range []string{
+		`&lt;script&gt;alert(1)&lt;/script&gt; \| a &amp; b`,
+		`&lt;img src="x" onerror="alert(1)"&gt;`,
+		"**Deprecated.** Use &lt;i&gt;CLOSED&lt;/i&gt;.",
+		"&lt;iframe src=x&gt;",
+		"&lt;b&gt;bold&lt;/b&gt;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %s:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// openAPIMethods are the HTTP methods that can be described as an OpenAPI
+// operation.
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Tags       []openAPITag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	CodeSamples []*openAPICodeSample        `json:"x-codeSamples,omitempty"`
+}
+
+// openAPICodeSample is a code sample in the format of the x-codeSamples
+// extension supported by Redoc.
+type openAPICodeSample struct {
+	Lang   string `json:"lang"`
+	Label  string `json:"label"`
+	Source string `json:"source"`
+}
+
+type openAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema  *Schema         `json:"schema"`
+	Example json.RawMessage `json:"example,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// OpenAPI renders all endpoints in the tags as a single OpenAPI 3.1 document
+// describing the provided version of the API. Each section becomes an OpenAPI
+// tag and every named type becomes a component schema. Routes that conflict
+// with the route of another endpoint are reported to d and left out.
+func OpenAPI(w io.Writer, tags map[string]*doc.Tag, version string, d *diag.List) error {
+	o := openAPI{
+		diags:     d,
+		endpoints: make(map[string]*doc.Endpoint),
+		routes:    make(map[*doc.Route]bool),
+		schemas: schemaBuilder{
+			ref: func(name string) string {
+				return "#/components/schemas/" + name
+			},
+		},
+		doc: &openAPIDocument{
+			OpenAPI: "3.1.0",
+			Info: openAPIInfo{
+				Title:   "API Reference",
+				Version: version,
+			},
+			Paths: make(map[string]map[string]*openAPIOperation),
+			Components: openAPIComponents{
+				Schemas: make(map[string]*Schema),
+			},
+		},
+	}
+	for _, section := range SortedSections(tags) {
+		o.tag(section, tags[section])
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(o.doc)
+}
+
+// openAPI is the state of an OpenAPI render.
+type openAPI struct {
+	schemas schemaBuilder
+	doc     *openAPIDocument
+	diags   *diag.List
+	// endpoints maps "<method> <path>" to the endpoint of the operation.
+	endpoints map[string]*doc.Endpoint
+	// routes is the set of routes rendered.
+	routes map[*doc.Route]bool
+}
+
+func (o openAPI) tag(section string, tag *doc.Tag) {
+	title := tagTitle(section, tag)
+	o.doc.Tags = append(o.doc.Tags, openAPITag{
+		Name:        title,
+		Description: strings.TrimSpace(tag.Preamble),
+	})
+	for _, pkg := range tag.Packages {
+		for name, typ := range pkg.Types {
+			o.doc.Components.Schemas[pkg.ID+"."+name] = o.schemas.typeSchema(typ)
+		}
+		for _, srv := range pkg.Services {
+			for _, e := range srv.Endpoints {
+				for i, r := range e.Routes {
+					o.route(title, pkg, srv, e, i, r)
+				}
+			}
+		}
+	}
+}
+
+// route adds the operation for the i-th route of the endpoint.
+func (o openAPI) route(tag string, pkg *doc.Package, srv *doc.Service, e *doc.Endpoint, i int, r *doc.Route) {
+	method := strings.ToLower(r.Method)
+	if !openAPIMethods[method] {
+		return
+	}
+	// The same route is rendered again if its package is in more than one
+	// section.
+	if o.routes[r] {
+		return
+	}
+	o.routes[r] = true
+	path := templatePath(r.Template)
+	key := method + " " + path
+	if other, ok := o.endpoints[key]; ok {
+		o.diags.Warnf(
+			e.Pos, "route %s %s of method %q conflicts with method %q",
+			r.Method, r.Path, e.FullMethod, other.FullMethod,
+		)
+		return
+	}
+	o.endpoints[key] = e
+	opID := endpointAnchor(pkg, srv, e)
+	if i > 0 {
+		opID += "_" + strconv.Itoa(i)
+	}
+	op := &openAPIOperation{
+		OperationID: opID,
+		Description: markdownDescription(e.Description, e.RichDescription),
+		Tags:        []string{tag},
+		Deprecated:  e.Deprecated || srv.Deprecated,
+		Responses: map[string]*openAPIResponse{
+			"200": {
+				Description: "A successful response.",
+				Content: map[string]openAPIMedia{
+					"application/json": {
+						Schema:  o.schemas.typeSchema(e.Response),
+						Example: e.ResponseExample,
+					},
+				},
+			},
+		},
+	}
+	for _, p := range r.PathParams {
+		description := p.Description
+		if p.Pattern != "*" {
+			description = strings.TrimSpace(description + "\n\nMust match `" + p.Pattern + "`.")
+		}
+		op.Parameters = append(op.Parameters, &openAPIParameter{
+			Name:        p.Name,
+			In:          "path",
+			Description: description,
+			Required:    true,
+			Deprecated:  p.Deprecated,
+			Schema:      o.schemas.typeSchema(p.Type),
+		})
+	}
+	for _, p := range r.QueryParams {
+		schema := o.schemas.typeSchema(p.Type)
+		if p.Repeated {
+			schema = &Schema{
+				Type:  "array",
+				Items: schema,
+			}
+		}
+		op.Parameters = append(op.Parameters, &openAPIParameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Deprecated:  p.Deprecated,
+			Schema:      schema,
+		})
+	}
+	req, _ := e.Request.(*doc.Message)
+	switch {
+	case r.BodyField == "*":
+		op.RequestBody = o.requestBody(e.Request, r.BodyExample)
+	case r.BodyField != "" && req != nil:
+		for _, f := range req.Fields {
+			if f.Name == r.BodyField {
+				op.RequestBody = o.requestBody(f.Type, r.BodyExample)
+			}
+		}
+	}
+	// Samples call the endpoint through the primary route.
+	if i == 0 {
+		for _, s := range e.Samples {
+			op.CodeSamples = append(op.CodeSamples, &openAPICodeSample{
+				Lang:   s.Lang,
+				Label:  s.Label,
+				Source: s.Code,
+			})
+		}
+	}
+	if o.doc.Paths[path] == nil {
+		o.doc.Paths[path] = make(map[string]*openAPIOperation)
+	}
+	o.doc.Paths[path][method] = op
+}
+
+func (o openAPI) requestBody(t doc.Type, example json.RawMessage) *openAPIRequestBody {
+	return &openAPIRequestBody{
+		Required: true,
+		Content: map[string]openAPIMedia{
+			"application/json": {
+				Schema:  o.schemas.typeSchema(t),
+				Example: example,
+			},
+		},
+	}
+}
+
+// templatePath returns the path template in the form used by OpenAPI, where
+// variables only consist of the field path.
+func templatePath(t *doc.PathTemplate) string {
+	var b strings.Builder
+	for _, seg := range t.Segments {
+		b.WriteByte('/')
+		if seg.Kind == doc.SegmentVariable {
+			b.WriteString("{" + seg.Variable.FieldPath + "}")
+			continue
+		}
+		b.WriteString(seg.Value)
+	}
+	if len(t.Segments) == 0 {
+		b.WriteByte('/')
+	}
+	if t.Verb != "" {
+		b.WriteString(":" + t.Verb)
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/diag"
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+	"github.com/chanbakjsd/protoc-gen-doc/proto"
+)
+
+// bookRoute returns a GET route with the path template, binding the name field
+// and passing view as a query parameter.
+func bookRoute(t *testing.T, path string) *doc.Route {
+	t.Helper()
+	tmpl, err := proto.ParseTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &doc.Route{
+		Method:   "GET",
+		Path:     path,
+		Template: tmpl,
+		PathParams: []*doc.Param{{
+			Name:    "name",
+			Pattern: "books/*",
+			Type:    &doc.Basic{Name: "string"},
+		}},
+		QueryParams: []*doc.Param{{
+			Name: "view",
+			Type: &doc.Basic{Name: "string"},
+		}},
+	}
+}
+
+// bookTags returns a tag with a GetBook endpoint and an endpoint sharing its
+// route.
+func bookTags(t *testing.T) map[string]*doc.Tag {
+	t.Helper()
+	request := &doc.Message{
+		Name: "GetBookRequest",
+		Fields: []*doc.Field{
+			{Name: "name", Type: &doc.Basic{Name: "string"}},
+			{Name: "view", Type: &doc.Basic{Name: "string"}},
+		},
+	}
+	get := &doc.Endpoint{
+		Name:       "GetBook",
+		FullMethod: "/shop.Books/GetBook",
+		Routes:     []*doc.Route{bookRoute(t, "/v1/{name=books/*}")},
+		Request:    request,
+		Response:   &doc.Message{Name: "Book"},
+	}
+	lookup := &doc.Endpoint{
+		Name:       "LookupBook",
+		FullMethod: "/shop.Books/LookupBook",
+		Routes:     []*doc.Route{bookRoute(t, "/v1/{name=books/*}")},
+		Request:    request,
+		Response:   &doc.Message{Name: "Book"},
+	}
+	pkg := &doc.Package{
+		ID:    "shop",
+		Types: map[string]doc.Type{},
+		Services: []*doc.Service{{
+			Name:      "Books",
+			Endpoints: []*doc.Endpoint{get, lookup},
+		}},
+	}
+	return map[string]*doc.Tag{
+		"default": {Packages: []*doc.Package{pkg}},
+		// A package may be in more than one section.
+		"other": {Packages: []*doc.Package{pkg}},
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	var buf bytes.Buffer
+	var d diag.List
+	if err := OpenAPI(&buf, bookTags(t), "2.3.0", &d); err != nil {
+		t.Fatal(err)
+	}
+	var out openAPIDocument
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Info.Version != "2.3.0" {
+		t.Errorf("version = %q, want %q", out.Info.Version, "2.3.0")
+	}
+	op := out.Paths["/v1/{name}"]["get"]
+	if op == nil {
+		t.Fatalf("operation not found in paths %v", out.Paths)
+	}
+	if op.OperationID != "shop.Books.GetBook" {
+		t.Errorf("operation ID = %q, want the first endpoint of the route", op.OperationID)
+	}
+	var params []string
+	for _, p := range op.Parameters {
+		params = append(params, p.In+":"+p.Name)
+	}
+	if got, want := strings.Join(params, ","), "path:name,query:view"; got != want {
+		t.Errorf("parameters = %q, want %q", got, want)
+	}
+	warnings := d.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %v, want a single conflict", warnings)
+	}
+	if want := `route GET /v1/{name=books/*} of method "/shop.Books/LookupBook" conflicts with method "/shop.Books/GetBook"`; warnings[0].Message != want {
+		t.Errorf("warning = %q, want %q", warnings[0].Message, want)
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "/"},
+		{"/v1/books", "/v1/books"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/{name}"},
+		{"/v1/{book.name}:publish", "/v1/{book.name}:publish"},
+		{"/v1/{parent}/books/**", "/v1/{parent}/books/**"},
+	}
+	for _, test := range tests {
+		tmpl, err := proto.ParseTemplate(test.path)
+		if err != nil {
+			t.Errorf("ParseTemplate(%q): %v", test.path, err)
+			continue
+		}
+		if got := templatePath(tmpl); got != test.want {
+			t.Errorf("templatePath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
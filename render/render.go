@@ -0,0 +1,166 @@
+// Package render renders documentation tags into output formats such as
+// Markdown, HTML and OpenAPI.
+package render
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// Index records where every named type is documented so that references can
+// be linked across packages and sections.
+type Index struct {
+	types map[string]location
+}
+
+// location is the place a named type is documented in.
+type location struct {
+	// section is the name of the section (the key of the tag).
+	section string
+	// name is the name of the type within its package.
+	name string
+}
+
+// NewIndex indexes all types in the provided tags. Types of packages shared by
+// multiple sections are linked to the first section in SortedSections order.
+func NewIndex(tags map[string]*doc.Tag) *Index {
+	idx := &Index{
+		types: make(map[string]location),
+	}
+	for _, section := range SortedSections(tags) {
+		for _, pkg := range tags[section].Packages {
+			for name := range pkg.Types {
+				if _, ok := idx.types[pkg.ID+"."+name]; ok {
+					continue
+				}
+				idx.types[pkg.ID+"."+name] = location{
+					section: section,
+					name:    name,
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// SortedSections returns the name of the sections ordered by the weight of
+// their tags, using the name to break ties.
+func SortedSections(tags map[string]*doc.Tag) []string {
+	sections := make([]string, 0, len(tags))
+	for section := range tags {
+		sections = append(sections, section)
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		a, b := tags[sections[i]], tags[sections[j]]
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		return sections[i] < sections[j]
+	})
+	return sections
+}
+
+// lookup returns the location of the type with the fully qualified name.
+func (i *Index) lookup(fullName string) (location, bool) {
+	loc, ok := i.types[fullName]
+	return loc, ok
+}
+
+// fieldNotes returns notes on the presence and behaviors of the field to be
+// placed after its type, such as "optional" or "output only".
+func fieldNotes(f *doc.Field) string {
+	var notes []string
+	seen := make(map[string]bool)
+	add := func(note string) {
+		if !seen[note] {
+			seen[note] = true
+			notes = append(notes, note)
+		}
+	}
+	if note := presenceNote(f); note != "" {
+		add(note)
+	}
+	if f.Packed {
+		add("packed")
+	}
+	for _, b := range f.Behaviors {
+		add(strings.ReplaceAll(strings.ToLower(b), "_", " "))
+	}
+	return strings.Join(notes, ", ")
+}
+
+// presenceNote returns a note on the presence of the field. Every field with
+// explicit presence is noted as optional, including message fields, except
+// for oneof members as their presence is implied by the oneof.
+func presenceNote(f *doc.Field) string {
+	switch f.Presence {
+	case doc.PresenceRequired:
+		return "required"
+	case doc.PresenceExplicit:
+		if f.Oneof != "" {
+			return ""
+		}
+		return "optional"
+	}
+	return ""
+}
+
+// fieldRanges returns the ranges of field numbers in text, such as
+// "5, 10 to 20, 1000 to max".
+func fieldRanges(ranges []*doc.FieldRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		end := strconv.Itoa(int(r.End))
+		if r.End == doc.MaxFieldNumber {
+			end = "max"
+		}
+		switch {
+		case r.Start == r.End:
+			parts = append(parts, end)
+		default:
+			parts = append(parts, strconv.Itoa(int(r.Start))+" to "+end)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typeAnchor returns the anchor of the type with the provided name in pkg.
+func typeAnchor(pkg *doc.Package, name string) string {
+	return pkg.ID + "." + name
+}
+
+// endpointAnchor returns the anchor of the endpoint.
+func endpointAnchor(pkg *doc.Package, srv *doc.Service, e *doc.Endpoint) string {
+	return pkg.ID + "." + srv.Name + "." + e.Name
+}
+
+// tagTitle returns the title of the tag, falling back to the section name if
+// the tag is unnamed.
+func tagTitle(section string, tag *doc.Tag) string {
+	if tag.Name != "" {
+		return tag.Name
+	}
+	return section
+}
+
+// sortedTypeNames returns the names of the types in the package in order.
+func sortedTypeNames(pkg *doc.Package) []string {
+	names := make([]string, 0, len(pkg.Types))
+	for name := range pkg.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markdownDescription returns the description as Markdown, using its rich text
+// if there is one.
+func markdownDescription(text string, rich *doc.RichText) string {
+	if rich == nil {
+		return text
+	}
+	return strings.TrimSpace(rich.Markdown)
+}
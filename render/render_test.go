@@ -0,0 +1,109 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+func TestFieldNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		field *doc.Field
+		want  string
+	}{
+		{"implicit", &doc.Field{Presence: doc.PresenceImplicit, Type: &doc.Basic{Name: "String"}}, ""},
+		{"repeated", &doc.Field{Presence: doc.PresenceRepeated, Type: &doc.Array{Value: &doc.Basic{Name: "String"}}}, ""},
+		{"proto3 optional", &doc.Field{Presence: doc.PresenceExplicit, Type: &doc.Basic{Name: "String"}}, "optional"},
+		{"enum", &doc.Field{Presence: doc.PresenceExplicit, Type: &doc.Ref{Name: "shop.Status"}}, "optional"},
+		{"message", &doc.Field{Presence: doc.PresenceExplicit, Type: &doc.Ref{Name: "shop.Book"}}, "optional"},
+		{"well-known message", &doc.Field{Presence: doc.PresenceExplicit, Type: &doc.Basic{Name: "Timestamp"}}, "optional"},
+		{"oneof member", &doc.Field{Presence: doc.PresenceExplicit, Oneof: "kind", Type: &doc.Ref{Name: "shop.Book"}}, ""},
+		{"proto2 required", &doc.Field{Presence: doc.PresenceRequired, Type: &doc.Basic{Name: "String"}}, "required"},
+		{"packed", &doc.Field{Presence: doc.PresenceRepeated, Packed: true}, "packed"},
+		{
+			"behaviors",
+			&doc.Field{
+				Presence:  doc.PresenceExplicit,
+				Behaviors: []string{doc.BehaviorRequired, doc.BehaviorOutputOnly},
+			},
+			"optional, required, output only",
+		},
+		{
+			"duplicate notes",
+			&doc.Field{Presence: doc.PresenceRequired, Behaviors: []string{doc.BehaviorRequired}},
+			"required",
+		},
+	}
+	for _, test := range tests {
+		if got := fieldNotes(test.field); got != test.want {
+			t.Errorf("%s: fieldNotes = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNewIndexSharedPackage(t *testing.T) {
+	pkg := &doc.Package{ID: "shop", Types: map[string]doc.Type{"Book": &doc.Message{Name: "Book"}}}
+	tags := map[string]*doc.Tag{
+		"b": {Weight: 1, Packages: []*doc.Package{pkg}},
+		"c": {Weight: 1, Packages: []*doc.Package{pkg}},
+		"a": {Weight: 2, Packages: []*doc.Package{pkg}},
+	}
+	for i := 0; i < 10; i++ {
+		loc, ok := NewIndex(tags).lookup("shop.Book")
+		if !ok || loc.section != "b" {
+			t.Fatalf("lookup(shop.Book) = %+v, %t, want section b", loc, ok)
+		}
+	}
+}
+
+// libraryTags returns two sections: "library" documents a book with a nested
+// enum and map, repeated and recursive fields, which references an author
+// documented in the "common" section.
+func libraryTags() map[string]*doc.Tag {
+	str := &doc.Basic{Name: "String", Encoding: &doc.Encoding{Type: "string"}}
+	book := &doc.Message{
+		Name:      "Book",
+		Recursive: true,
+		Fields: []*doc.Field{
+			{Name: "title", Number: 1, Type: str, Description: "The title."},
+			{Name: "formats", Number: 2, Type: &doc.Map{Key: str, Value: &doc.Ref{Name: "lib.Book.Format"}}},
+			{Name: "authors", Number: 3, Type: &doc.Array{Value: &doc.Ref{Name: "common.Author"}}},
+			{Name: "sequel", Number: 4, Type: &doc.Ref{Name: "lib.Book", Recursive: true}},
+		},
+	}
+	format := &doc.Enum{
+		Name:   "Book.Format",
+		Parent: "Book",
+		Values: []*doc.EnumVal{
+			{Value: "FORMAT_UNSPECIFIED", DisplayName: "FORMAT_UNSPECIFIED", Default: true},
+			{Value: "FORMAT_PAPERBACK", DisplayName: "FORMAT_PAPERBACK", Number: 1},
+		},
+	}
+	author := &doc.Message{
+		Name:   "Author",
+		Fields: []*doc.Field{{Name: "name", Number: 1, Type: str}},
+	}
+	lib := &doc.Package{
+		ID:    "lib",
+		Types: map[string]doc.Type{"Book": book, "Book.Format": format},
+		Services: []*doc.Service{{
+			Name: "Library",
+			Endpoints: []*doc.Endpoint{{
+				Name:      "GetBook",
+				Request:   &doc.Message{Name: "GetBookRequest"},
+				Response:  &doc.Ref{Name: "lib.Book"},
+				Streaming: doc.StreamingUnary,
+				Routes:    []*doc.Route{{Method: "GET", Path: "/v1/book"}},
+			}},
+		}},
+	}
+	common := &doc.Package{
+		ID:    "common",
+		Types: map[string]doc.Type{"Author": author},
+	}
+	return map[string]*doc.Tag{
+		"library": {Name: "Library", Weight: 1, Packages: []*doc.Package{lib}},
+		"common":  {Name: "Common", Weight: 2, Packages: []*doc.Package{common}},
+	}
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"encoding/json"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// Schema is a JSON Schema (draft 2020-12) or one of its subschemas.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Examples             []json.RawMessage  `json:"examples,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+	// Nullable allows null in addition to Type.
+	Nullable bool `json:"-"`
+}
+
+// MarshalJSON marshals the schema, listing "null" in the type if the schema is
+// nullable.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	if !s.Nullable || s.Type == "" || s.Type == "null" {
+		return json.Marshal(schema(s))
+	}
+	return json.Marshal(struct {
+		Type []string `json:"type"`
+		schema
+	}{
+		Type:   []string{s.Type, "null"},
+		schema: schema(s),
+	})
+}
+
+// schemaBuilder converts doc types to JSON Schema.
+type schemaBuilder struct {
+	// ref returns the reference to the named type with the fully qualified
+	// name.
+	ref func(name string) string
+}
+
+// typeSchema returns the schema of the type.
+func (b schemaBuilder) typeSchema(t doc.Type) *Schema {
+	switch t := t.(type) {
+	case *doc.Basic:
+		s := &Schema{}
+		if t.Encoding != nil {
+			s = encodingSchema(t.Encoding)
+		}
+		if t.Example != "" {
+			s.Examples = []json.RawMessage{json.RawMessage(t.Example)}
+		}
+		return s
+	case *doc.Ref:
+		return &Schema{Ref: b.ref(t.Name)}
+	case *doc.Array:
+		return &Schema{
+			Type:  "array",
+			Items: b.typeSchema(t.Value),
+		}
+	case *doc.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: b.typeSchema(t.Value),
+		}
+	case *doc.Message:
+		return b.messageSchema(t)
+	case *doc.Enum:
+		return b.enumSchema(t)
+	}
+	return &Schema{}
+}
+
+// encodingSchema returns the schema of a basic type with the JSON encoding.
+// Alternate representations are accepted with anyOf.
+func encodingSchema(e *doc.Encoding) *Schema {
+	s := &Schema{
+		Description:     e.Description,
+		Type:            e.Type,
+		Format:          e.Format,
+		ContentEncoding: e.ContentEncoding,
+		Enum:            e.Enum,
+		Nullable:        e.Nullable,
+	}
+	if len(e.Alternates) == 0 {
+		return s
+	}
+	anyOf := &Schema{
+		Description: s.Description,
+		AnyOf:       []*Schema{s},
+	}
+	s.Description = ""
+	for _, alt := range e.Alternates {
+		alt := encodingSchema(alt)
+		alt.Nullable = e.Nullable
+		anyOf.AnyOf = append(anyOf.AnyOf, alt)
+	}
+	return anyOf
+}
+
+// messageSchema returns the schema of the message.
+func (b schemaBuilder) messageSchema(m *doc.Message) *Schema {
+	s := &Schema{
+		Title:       m.Name,
+		Description: markdownDescription(m.Description, m.RichDescription),
+		Deprecated:  m.Deprecated,
+		Type:        "object",
+		Properties:  make(map[string]*Schema, len(m.Fields)),
+	}
+	for _, f := range m.Fields {
+		s.Properties[f.Name] = b.fieldSchema(f)
+		if f.Presence == doc.PresenceRequired || f.HasBehavior(doc.BehaviorRequired) {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	for _, o := range m.Oneofs {
+		s.AllOf = append(s.AllOf, oneofSchema(o))
+	}
+	return s
+}
+
+// oneofSchema returns the schema that allows at most one of the fields in the
+// oneof to be present: exactly one of them is set, or none of them are.
+func oneofSchema(o *doc.Oneof) *Schema {
+	s := &Schema{
+		Description: o.Description,
+	}
+	none := &Schema{}
+	for _, name := range o.Fields {
+		s.OneOf = append(s.OneOf, &Schema{Required: []string{name}})
+		none.AnyOf = append(none.AnyOf, &Schema{Required: []string{name}})
+	}
+	s.OneOf = append(s.OneOf, &Schema{Not: none})
+	return s
+}
+
+// fieldSchema returns the schema of the field.
+func (b schemaBuilder) fieldSchema(f *doc.Field) *Schema {
+	s := b.typeSchema(f.Type)
+	if f.Description != "" {
+		s.Description = f.Description
+	}
+	s.ReadOnly = f.HasBehavior(doc.BehaviorOutputOnly)
+	s.WriteOnly = f.HasBehavior(doc.BehaviorInputOnly)
+	s.Deprecated = f.Deprecated
+	return s
+}
+
+// enumSchema returns the schema of the enum.
+func (b schemaBuilder) enumSchema(e *doc.Enum) *Schema {
+	s := &Schema{
+		Title:       e.Name,
+		Description: markdownDescription(e.Description, e.RichDescription),
+		Deprecated:  e.Deprecated,
+		Type:        "string",
+		Enum:        make([]string, 0, len(e.Values)),
+	}
+	for _, v := range e.Values {
+		s.Enum = append(s.Enum, v.Value)
+	}
+	return s
+}
+
+// JSONSchema returns the standalone JSON Schema document of the named type
+// with the fully qualified name. References to other types point to their
+// documents as named by JSONSchemaFile.
+func JSONSchema(fullName string, t doc.Type) *Schema {
+	b := schemaBuilder{
+		ref: JSONSchemaFile,
+	}
+	s := b.typeSchema(t)
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.ID = JSONSchemaFile(fullName)
+	return s
+}
+
+// JSONSchemaFile returns the name of the file containing the JSON Schema of
+// the type with the fully qualified name.
+func JSONSchemaFile(fullName string) string {
+	return fullName + ".schema.json"
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanbakjsd/protoc-gen-doc/doc"
+)
+
+// compactJSON returns the JSON with its object keys sorted and without
+// spaces.
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestJSONSchema(t *testing.T) {
+	types := libraryTags()["library"].Packages[0].Types
+	tests := []struct {
+		name string
+		typ  doc.Type
+		want string
+	}{
+		{
+			"lib.Book", types["Book"],
+			`{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$id": "lib.Book.schema.json",
+				"title": "Book",
+				"type": "object",
+				"properties": {
+					"authors": {"type": "array", "items": {"$ref": "common.Author.schema.json"}},
+					"formats": {"type": "object", "additionalProperties": {"$ref": "lib.Book.Format.schema.json"}},
+					"sequel": {"$ref": "lib.Book.schema.json"},
+					"title": {"description": "The title.", "type": "string"}
+				}
+			}`,
+		},
+		{
+			"lib.Book.Format", types["Book.Format"],
+			`{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$id": "lib.Book.Format.schema.json",
+				"title": "Book.Format",
+				"type": "string",
+				"enum": ["FORMAT_UNSPECIFIED", "FORMAT_PAPERBACK"]
+			}`,
+		},
+	}
+	for _, test := range tests {
+		b, err := json.Marshal(JSONSchema(test.name, test.typ))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := compactJSON(t, string(b)), compactJSON(t, test.want); got != want {
+			t.Errorf("JSONSchema(%s) =\n%s\nwant\n%s", test.name, got, want)
+		}
+	}
+}